@@ -83,9 +83,13 @@ func buildDependencyContainer() *Container {
 	getEnvironmentUseCase := envUseCases.NewGetEnvUseCase()
 	switchEnvironmentUseCase := envUseCases.NewSwitchEnvUseCase()
 	deleteEnvironmentUseCase := envUseCases.NewDeleteEnvUseCase()
+	promoteEnvironmentUseCase := envUseCases.NewPromoteEnvUseCase()
 
 	pullUseCase := syncUseCase.NewPullUseCase()
 	pushUseCase := syncUseCase.NewPushUseCase()
+	listBackupsUseCase := syncUseCase.NewListBackupsUseCase()
+	restoreUseCase := syncUseCase.NewRestoreUseCase()
+	diffUseCase := syncUseCase.NewDiffUseCase()
 
 	initUC := inituc.NewInitUseCase()
 
@@ -94,6 +98,8 @@ func buildDependencyContainer() *Container {
 	fetchAppUseCase := run.NewFetchAppUseCase()
 	readConfigUseCase := run.NewReadConfigUseCase()
 	runUseCase := run.NewRedactor()
+	runProcessesUseCase := run.NewRunProcessesUseCase()
+	watchUseCase := run.NewWatchUseCase(injectUseCase, injectSecretUseCase)
 
 	genPEMKeyUseCase := genpem.NewGenKeyPairUseCase()
 
@@ -123,12 +129,16 @@ func buildDependencyContainer() *Container {
 		getEnvironmentUseCase,
 		switchEnvironmentUseCase,
 		deleteEnvironmentUseCase,
+		promoteEnvironmentUseCase,
 		envFormatter,
 	)
 
 	c.SyncHandler = handlers.NewSyncHandler(
 		pullUseCase,
 		pushUseCase,
+		listBackupsUseCase,
+		restoreUseCase,
+		diffUseCase,
 		syncFormatter,
 	)
 
@@ -139,6 +149,8 @@ func buildDependencyContainer() *Container {
 
 	c.RunHandler = handlers.NewRunHandler(
 		runUseCase,
+		runProcessesUseCase,
+		watchUseCase,
 		injectUseCase,
 		injectSecretUseCase,
 		fetchAppUseCase,
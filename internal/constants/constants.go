@@ -2,5 +2,17 @@ package constants
 
 const (
 	DefaultProjectConfig = "envsyncrc.toml"
+	DefaultEnvFile       = ".env"
 	LoggerKey            = "logger"
+
+	// ProjectStateDir holds local sync state next to the project configuration
+	ProjectStateDir = ".envsync"
+	SnapshotDir     = "snapshots"
+	BackupDir       = "backups"
+
+	// DefaultBackupRetention is the number of backups kept per env file
+	DefaultBackupRetention = 10
+
+	// DefaultDeleteThreshold is the number of remote deletions a push may make without confirmation
+	DefaultDeleteThreshold = 10
 )
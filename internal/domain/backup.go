@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+// EnvBackup is a copy of a local env file saved before it was overwritten
+type EnvBackup struct {
+	// ID is the UTC timestamp of the backup, also used as its file name
+	ID        string    `json:"id"`
+	EnvFile   string    `json:"env_file"`
+	Path      string    `json:"path"`
+	CreatedAt time.Time `json:"created_at"`
+}
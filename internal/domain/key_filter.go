@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"fmt"
+	"path"
+	"slices"
+)
+
+// KeyFilter selects the keys taking part in a sync. Patterns are globs as
+// understood by path.Match, so a prefix is written as "DB_*".
+type KeyFilter struct {
+	// Include limits the sync to matching keys. An empty list includes every key.
+	Include []string
+	// Exclude leaves matching keys alone, even when they are included
+	Exclude []string
+}
+
+// Validate checks that every pattern is a valid glob
+func (f KeyFilter) Validate() error {
+	for _, pattern := range slices.Concat(f.Include, f.Exclude) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid key pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// Matches returns true if key takes part in the sync
+func (f KeyFilter) Matches(key string) bool {
+	if len(f.Include) > 0 && !matchesAny(f.Include, key) {
+		return false
+	}
+	return !matchesAny(f.Exclude, key)
+}
+
+func matchesAny(patterns []string, key string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, key); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Process is a named command line run by envsync run next to others
+type Process struct {
+	Name    string
+	Command string
+}
+
+var procfileLineRegex = regexp.MustCompile(`^([A-Za-z0-9_-]+):\s*(.+)$`)
+
+// ParseProcfile reads the processes of a Procfile, one "name: command" per
+// line. Blank lines and lines starting with # are skipped.
+func ParseProcfile(data []byte) ([]Process, error) {
+	var processes []Process
+	seen := make(map[string]bool)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		m := procfileLineRegex.FindStringSubmatch(text)
+		if m == nil {
+			return nil, fmt.Errorf("line %d: expected \"name: command\"", line)
+		}
+		if seen[m[1]] {
+			return nil, fmt.Errorf("line %d: process %q is defined more than once", line, m[1])
+		}
+		seen[m[1]] = true
+
+		processes = append(processes, Process{Name: m[1], Command: m[2]})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(processes) == 0 {
+		return nil, fmt.Errorf("no processes defined")
+	}
+	return processes, nil
+}
+
+// ProcessList returns the processes of the [processes] table ordered by name
+func (c SyncConfig) ProcessList() ([]Process, error) {
+	processes := make([]Process, 0, len(c.Processes))
+	for name, command := range c.Processes {
+		if !procfileLineRegex.MatchString(name + ": " + command) {
+			return nil, fmt.Errorf("invalid process %q, names use letters, digits, - and _ and need a command", name)
+		}
+		processes = append(processes, Process{Name: name, Command: command})
+	}
+
+	slices.SortFunc(processes, func(a, b Process) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return processes, nil
+}
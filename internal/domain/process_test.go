@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseProcfile(t *testing.T) {
+	procfile := `# local development
+web: npm run dev -- --port $PORT
+
+worker:   node worker.js
+release-tasks: ./migrate up && echo done
+`
+
+	processes, err := ParseProcfile([]byte(procfile))
+	if err != nil {
+		t.Fatalf("ParseProcfile returned error: %v", err)
+	}
+
+	expected := []Process{
+		{Name: "web", Command: "npm run dev -- --port $PORT"},
+		{Name: "worker", Command: "node worker.js"},
+		{Name: "release-tasks", Command: "./migrate up && echo done"},
+	}
+	if !slices.Equal(processes, expected) {
+		t.Errorf("Expected %v, got %v", expected, processes)
+	}
+
+	for _, invalid := range []string{"", "# nothing\n", "web npm start\n", "web:\n", "web: a\nweb: b\n"} {
+		if _, err := ParseProcfile([]byte(invalid)); err == nil {
+			t.Errorf("Expected an error for %q", invalid)
+		}
+	}
+}
+
+func TestProcessListIsOrderedByName(t *testing.T) {
+	cfg := SyncConfig{Processes: map[string]string{"worker": "node worker.js", "web": "npm start"}}
+
+	processes, err := cfg.ProcessList()
+	if err != nil {
+		t.Fatalf("ProcessList returned error: %v", err)
+	}
+	if len(processes) != 2 || processes[0].Name != "web" || processes[1].Name != "worker" {
+		t.Errorf("Unexpected processes %v", processes)
+	}
+
+	cfg.Processes["bad name"] = "true"
+	if _, err := cfg.ProcessList(); err == nil {
+		t.Error("Expected an error for an invalid name")
+	}
+}
@@ -0,0 +1,218 @@
+package domain
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// RedactMode is the strategy used to find values in the output
+type RedactMode string
+
+const (
+	// RedactModeExact replaces every occurrence of a value, even inside other words
+	RedactModeExact RedactMode = "exact"
+	// RedactModeWord replaces long values anywhere and short values only
+	// where they stand as a word of their own, leaving paths and names alone
+	RedactModeWord RedactMode = "word"
+	// RedactModeEntropy works like RedactModeWord and also hides tokens that
+	// look random enough to be credentials envsync does not know about
+	RedactModeEntropy RedactMode = "entropy"
+)
+
+// DefaultRedactMode is used when no mode is configured
+const DefaultRedactMode = RedactModeWord
+
+// RedactModes lists every supported mode
+var RedactModes = []RedactMode{RedactModeExact, RedactModeWord, RedactModeEntropy}
+
+// ParseRedactMode returns the mode named s, or DefaultRedactMode when s is empty
+func ParseRedactMode(s string) (RedactMode, error) {
+	if s == "" {
+		return DefaultRedactMode, nil
+	}
+
+	mode := RedactMode(s)
+	if !slices.Contains(RedactModes, mode) {
+		return "", fmt.Errorf("unknown redact mode %q, expected one of %v", s, RedactModes)
+	}
+	return mode, nil
+}
+
+// RedactEncoding is a transform of secret values that is redacted along with the raw value
+type RedactEncoding string
+
+const (
+	RedactEncodingBase64    RedactEncoding = "base64"
+	RedactEncodingURL       RedactEncoding = "url"
+	RedactEncodingJSON      RedactEncoding = "json"
+	RedactEncodingHex       RedactEncoding = "hex"
+	RedactEncodingBasicAuth RedactEncoding = "basic_auth"
+)
+
+// RedactEncodings lists every supported transform
+var RedactEncodings = []RedactEncoding{
+	RedactEncodingBase64,
+	RedactEncodingURL,
+	RedactEncodingJSON,
+	RedactEncodingHex,
+	RedactEncodingBasicAuth,
+}
+
+// RedactPolicy overrides how the value of a single key is redacted
+type RedactPolicy string
+
+const (
+	// RedactPolicyNever leaves the value visible
+	RedactPolicyNever RedactPolicy = "never"
+	// RedactPolicyAlways hides every occurrence of the value, whatever the mode
+	RedactPolicyAlways RedactPolicy = "always"
+	// RedactPolicyPartial shows the start and the last characters of the value, as in sk_live_****abcd
+	RedactPolicyPartial RedactPolicy = "partial"
+)
+
+// RedactPolicies lists every supported policy
+var RedactPolicies = []RedactPolicy{RedactPolicyNever, RedactPolicyAlways, RedactPolicyPartial}
+
+// DefaultRedactPlaceholder replaces values when no placeholder is configured
+const DefaultRedactPlaceholder = "[REDACTED]"
+
+// RedactKeyPlaceholder is replaced by the name of the variable in a placeholder
+const RedactKeyPlaceholder = "{key}"
+
+// RedactConfig configures how values are hidden from the output of envsync run
+type RedactConfig struct {
+	// Mode is one of RedactModes, DefaultRedactMode when empty
+	Mode RedactMode `toml:"mode,omitempty"`
+	// Placeholder replaces hidden values, DefaultRedactPlaceholder when empty.
+	// RedactKeyPlaceholder in it becomes the name of the variable, as in "[REDACTED:{key}]".
+	Placeholder string `toml:"placeholder,omitempty"`
+	// Allow lists globs of keys whose values are harmless and never redacted, such as NODE_ENV
+	Allow []string `toml:"allow,omitempty"`
+	// Keys maps globs of keys to the policy of their values. An exact key
+	// takes precedence over globs, and both over Allow. Of several matching
+	// globs the most specific wins, see PolicyFor.
+	Keys map[string]RedactPolicy `toml:"keys,omitempty"`
+	// Encodings turns the transforms of RedactEncodings on or off. Transforms
+	// that are not listed are enabled.
+	Encodings map[RedactEncoding]bool `toml:"encodings,omitempty"`
+}
+
+// EnabledEncodings returns the transforms to redact, in the order of RedactEncodings
+func (c RedactConfig) EnabledEncodings() ([]RedactEncoding, error) {
+	for encoding := range c.Encodings {
+		if !slices.Contains(RedactEncodings, encoding) {
+			return nil, fmt.Errorf("unknown redact encoding %q, expected one of %v", encoding, RedactEncodings)
+		}
+	}
+
+	enabled := make([]RedactEncoding, 0, len(RedactEncodings))
+	for _, encoding := range RedactEncodings {
+		if on, ok := c.Encodings[encoding]; !ok || on {
+			enabled = append(enabled, encoding)
+		}
+	}
+	return enabled, nil
+}
+
+// Validate checks the key globs and policies
+func (c RedactConfig) Validate() error {
+	for _, pattern := range c.Allow {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid allow pattern %q: %w", pattern, err)
+		}
+	}
+
+	for pattern, policy := range c.Keys {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid key pattern %q: %w", pattern, err)
+		}
+		if !slices.Contains(RedactPolicies, policy) {
+			return fmt.Errorf("unknown redact policy %q for %q, expected one of %v", policy, pattern, RedactPolicies)
+		}
+	}
+
+	return nil
+}
+
+// PolicyFor returns the policy of key, or an empty policy when the mode decides.
+// When several globs match key, the one with the fewest wildcards wins, then
+// the one with the longest literal prefix, then the one with the most literal
+// characters, then the first in sort order.
+func (c RedactConfig) PolicyFor(key string) RedactPolicy {
+	if policy, ok := c.Keys[key]; ok {
+		return policy
+	}
+
+	// The most specific matching glob wins, so "AWS_*" overrides "*"
+	best := ""
+	found := false
+	for pattern := range c.Keys {
+		if ok, _ := path.Match(pattern, key); ok && (!found || moreSpecificGlob(pattern, best)) {
+			best, found = pattern, true
+		}
+	}
+	if found {
+		return c.Keys[best]
+	}
+
+	if matchesAny(c.Allow, key) {
+		return RedactPolicyNever
+	}
+	return ""
+}
+
+// moreSpecificGlob reports whether glob a matches fewer keys than glob b, see
+// PolicyFor
+func moreSpecificGlob(a, b string) bool {
+	wildcardsA, prefixA, literalsA := globSpecificity(a)
+	wildcardsB, prefixB, literalsB := globSpecificity(b)
+	switch {
+	case wildcardsA != wildcardsB:
+		return wildcardsA < wildcardsB
+	case prefixA != prefixB:
+		return prefixA > prefixB
+	case literalsA != literalsB:
+		return literalsA > literalsB
+	}
+	return a < b
+}
+
+// globSpecificity returns the number of wildcards of a path.Match glob, a
+// character class counting as one, the length of its literal prefix and its
+// number of literal characters
+func globSpecificity(glob string) (wildcards, prefix, literals int) {
+	for i := 0; i < len(glob); i++ {
+		switch glob[i] {
+		case '*', '?':
+			wildcards++
+		case '[':
+			wildcards++
+			for i < len(glob) && glob[i] != ']' {
+				i++
+			}
+		case '\\':
+			i++
+			fallthrough
+		default:
+			literals++
+			if wildcards == 0 {
+				prefix++
+			}
+		}
+	}
+	return wildcards, prefix, literals
+}
+
+// RenderRedactPlaceholder fills in template for a value of key, see
+// RedactConfig.Placeholder. An unknown key is shown as "?".
+func RenderRedactPlaceholder(template, key string) string {
+	if template == "" {
+		return DefaultRedactPlaceholder
+	}
+	if key == "" {
+		key = "?"
+	}
+	return strings.ReplaceAll(template, RedactKeyPlaceholder, key)
+}
@@ -0,0 +1,73 @@
+package domain
+
+import "testing"
+
+func TestRedactConfigPolicyFor(t *testing.T) {
+	cfg := RedactConfig{
+		Allow: []string{"NODE_ENV", "PUBLIC_*"},
+		Keys: map[string]RedactPolicy{
+			"STRIPE_*":        RedactPolicyPartial,
+			"STRIPE_WEBHOOK":  RedactPolicyAlways,
+			"PUBLIC_API_HOST": RedactPolicyAlways,
+		},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate returned error: %v", err)
+	}
+
+	expected := map[string]RedactPolicy{
+		"NODE_ENV":        RedactPolicyNever,
+		"PUBLIC_URL":      RedactPolicyNever,
+		"STRIPE_KEY":      RedactPolicyPartial,
+		"STRIPE_WEBHOOK":  RedactPolicyAlways,
+		"PUBLIC_API_HOST": RedactPolicyAlways,
+		"DATABASE_URL":    "",
+	}
+	for key, policy := range expected {
+		if got := cfg.PolicyFor(key); got != policy {
+			t.Errorf("PolicyFor(%q) = %q, expected %q", key, got, policy)
+		}
+	}
+}
+
+func TestRedactConfigPolicyForOverlappingGlobs(t *testing.T) {
+	cfg := RedactConfig{
+		Keys: map[string]RedactPolicy{
+			"*":            RedactPolicyNever,
+			"A*":           RedactPolicyPartial,
+			"AWS_*":        RedactPolicyAlways,
+			"AWS_*_KEY":    RedactPolicyNever,
+			"AWS_SECRET_*": RedactPolicyPartial,
+			"DB_?":         RedactPolicyAlways,
+		},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate returned error: %v", err)
+	}
+
+	expected := map[string]RedactPolicy{
+		"HOME":                  RedactPolicyNever,
+		"API_TOKEN":             RedactPolicyPartial,
+		"AWS_REGION":            RedactPolicyAlways,
+		"AWS_SECRET_ACCESS_KEY": RedactPolicyPartial,
+		"AWS_ACCESS_KEY":        RedactPolicyNever,
+		"DB_1":                  RedactPolicyAlways,
+	}
+	for key, policy := range expected {
+		if got := cfg.PolicyFor(key); got != policy {
+			t.Errorf("PolicyFor(%q) = %q, expected %q", key, got, policy)
+		}
+	}
+}
+
+func TestRedactConfigValidate(t *testing.T) {
+	invalid := []RedactConfig{
+		{Allow: []string{"["}},
+		{Keys: map[string]RedactPolicy{"KEY": "sometimes"}},
+	}
+	for _, cfg := range invalid {
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("Expected an error for %+v", cfg)
+		}
+	}
+}
@@ -1,6 +1,12 @@
 package domain
 
-import "time"
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
 
 // EnvironmentVariable represents a single environment variable
 type EnvironmentVariable struct {
@@ -12,16 +18,174 @@ type EnvironmentVariable struct {
 type EnvironmentSync struct {
 	Local      map[string]string
 	Remote     map[string]EnvironmentVariable
+	Base       map[string]string
+	Filter     KeyFilter
 	ToAdd      []EnvironmentVariable
 	ToUpdate   []EnvironmentVariable
 	ToDelete   []string
 	LastSynced time.Time
 }
 
+// SyncSnapshot is the last state both sides agreed on, used as the merge base
+type SyncSnapshot struct {
+	AppID     string `json:"app_id"`
+	EnvTypeID string `json:"env_type_id"`
+	// EnvFile is the env file synced, relative to the project configuration
+	EnvFile    string            `json:"env_file,omitempty"`
+	Variables  map[string]string `json:"variables"`
+	LastSynced time.Time         `json:"last_synced"`
+}
+
+// EnvironmentConflict represents a key that changed differently on both sides since the last sync
+type EnvironmentConflict struct {
+	Key           string
+	BaseValue     string
+	LocalValue    string
+	RemoteValue   string
+	InBase        bool
+	LocalDeleted  bool
+	RemoteDeleted bool
+}
+
+// ConflictResolution is the value chosen for a conflicting key
+type ConflictResolution struct {
+	Key    string
+	Value  string
+	Delete bool
+}
+
+// KeepLocal resolves the conflict with the local value
+func (c EnvironmentConflict) KeepLocal() ConflictResolution {
+	return ConflictResolution{Key: c.Key, Value: c.LocalValue, Delete: c.LocalDeleted}
+}
+
+// TakeRemote resolves the conflict with the remote value
+func (c EnvironmentConflict) TakeRemote() ConflictResolution {
+	return ConflictResolution{Key: c.Key, Value: c.RemoteValue, Delete: c.RemoteDeleted}
+}
+
+// ChangeSet groups the additions, updates and deletions made on one side
+type ChangeSet struct {
+	Added   []EnvironmentVariable
+	Updated []EnvironmentVariable
+	Deleted []EnvironmentVariable
+}
+
+// IsEmpty returns true if the change set contains no changes
+func (cs ChangeSet) IsEmpty() bool {
+	return len(cs.Added) == 0 && len(cs.Updated) == 0 && len(cs.Deleted) == 0
+}
+
+// MergeResult is the outcome of a three-way merge between base, local and remote
+type MergeResult struct {
+	LocalChanges  ChangeSet
+	RemoteChanges ChangeSet
+	Conflicts     []EnvironmentConflict
+}
+
 // SyncConfig represents the configuration needed for syncing
 type SyncConfig struct {
 	AppID     string `toml:"app_id"`
 	EnvTypeID string `toml:"env_type_id"`
+	// EnvFile is the local file synced with EnvTypeID, relative to the config file
+	EnvFile string `toml:"env_file,omitempty"`
+	// Include and Exclude are key globs limiting which variables are synced
+	Include []string `toml:"include,omitempty"`
+	Exclude []string `toml:"exclude,omitempty"`
+	// DeleteThreshold is the number of remote deletions a push may make
+	// without confirmation. Zero uses the default, a negative value disables the check.
+	DeleteThreshold int `toml:"delete_threshold,omitempty"`
+	// BackupRetention is the number of backups kept per env file. Zero uses
+	// the default, a negative value disables backups.
+	BackupRetention int `toml:"backup_retention,omitempty"`
+	// Files maps local files to environment types. When set, pull and push
+	// sync every listed file instead of the EnvFile/EnvTypeID pair.
+	Files []EnvFileMapping `toml:"files,omitempty"`
+	// Redact configures how envsync run hides values in the output of the command
+	Redact RedactConfig `toml:"redact,omitempty"`
+	// Processes are the commands envsync run starts together when no command
+	// is given, keyed by name
+	Processes map[string]string `toml:"processes,omitempty"`
+	// FileSecrets are secrets envsync run writes to files, keyed by secret.
+	// The variable holds the path of the file, named after the value or the key.
+	FileSecrets map[string]string `toml:"file_secrets,omitempty"`
+}
+
+// EnvFileMapping links a local env file to an environment type given by name or ID
+type EnvFileMapping struct {
+	Path    string `toml:"path"`
+	EnvType string `toml:"env_type"`
+}
+
+// SyncTarget is a local env file along with the environment type it is synced with
+type SyncTarget struct {
+	EnvFile string
+	EnvType EnvType
+}
+
+// Targets resolves the env files to sync against the environment types of the app
+func (c SyncConfig) Targets(envTypes []EnvType) ([]SyncTarget, error) {
+	if len(c.Files) == 0 {
+		envType, ok := FindEnvType(envTypes, c.EnvTypeID)
+		if !ok {
+			return nil, fmt.Errorf("environment type %q not found", c.EnvTypeID)
+		}
+		return []SyncTarget{{EnvFile: c.EnvFile, EnvType: envType}}, nil
+	}
+
+	targets := make([]SyncTarget, 0, len(c.Files))
+	seenFiles := make(map[string]bool, len(c.Files))
+	seenTypes := make(map[string]bool, len(c.Files))
+	for _, m := range c.Files {
+		if m.Path == "" {
+			return nil, fmt.Errorf("missing path for environment type %q", m.EnvType)
+		}
+		envType, ok := FindEnvType(envTypes, m.EnvType)
+		if !ok {
+			return nil, fmt.Errorf("environment type %q for %s not found", m.EnvType, m.Path)
+		}
+		// Each file and environment type has its own merge base, so they can only be mapped once
+		if seenFiles[m.Path] {
+			return nil, fmt.Errorf("file %s is mapped more than once", m.Path)
+		}
+		if seenTypes[envType.ID] {
+			return nil, fmt.Errorf("environment type %s is mapped more than once", envType.Name)
+		}
+		seenFiles[m.Path] = true
+		seenTypes[envType.ID] = true
+
+		targets = append(targets, SyncTarget{EnvFile: m.Path, EnvType: envType})
+	}
+
+	return targets, nil
+}
+
+// EnvFiles returns the local env files of the project as configured, without resolving environment types
+func (c SyncConfig) EnvFiles() []string {
+	if len(c.Files) == 0 {
+		return []string{c.EnvFile}
+	}
+
+	files := make([]string, len(c.Files))
+	for i, m := range c.Files {
+		files[i] = m.Path
+	}
+	return files
+}
+
+// FindEnvType looks up an environment type by ID, or by name ignoring case
+func FindEnvType(envTypes []EnvType, ref string) (EnvType, bool) {
+	for _, et := range envTypes {
+		if et.ID == ref {
+			return et, true
+		}
+	}
+	for _, et := range envTypes {
+		if strings.EqualFold(et.Name, ref) {
+			return et, true
+		}
+	}
+	return EnvType{}, false
 }
 
 // NewEnvironmentSync creates a new EnvironmentSync instance
@@ -45,6 +209,9 @@ func (es *EnvironmentSync) CalculateDiff() {
 
 	// Find variables to add or update
 	for key, localValue := range es.Local {
+		if !es.Filter.Matches(key) {
+			continue
+		}
 		if remoteVar, exists := es.Remote[key]; exists {
 			// Variable exists in both - check if it needs updating
 			if remoteVar.Value != localValue {
@@ -64,12 +231,125 @@ func (es *EnvironmentSync) CalculateDiff() {
 
 	// Find variables to delete (only in remote)
 	for key := range es.Remote {
+		if !es.Filter.Matches(key) {
+			continue
+		}
 		if _, exists := es.Local[key]; !exists {
 			es.ToDelete = append(es.ToDelete, key)
 		}
 	}
 }
 
+// Merge performs a three-way merge of Local and Remote against Base.
+// A key changed on only one side is reported as a change of that side,
+// a key changed differently on both sides is reported as a conflict.
+func (es *EnvironmentSync) Merge() MergeResult {
+	var result MergeResult
+
+	keys := make(map[string]struct{}, len(es.Local)+len(es.Remote)+len(es.Base))
+	for key := range es.Local {
+		keys[key] = struct{}{}
+	}
+	for key := range es.Remote {
+		keys[key] = struct{}{}
+	}
+	for key := range es.Base {
+		keys[key] = struct{}{}
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(keys)) {
+		if !es.Filter.Matches(key) {
+			continue
+		}
+
+		localValue, inLocal := es.Local[key]
+		remoteVar, inRemote := es.Remote[key]
+		baseValue, inBase := es.Base[key]
+		remoteValue := remoteVar.Value
+
+		localChanged := inLocal != inBase || localValue != baseValue
+		remoteChanged := inRemote != inBase || remoteValue != baseValue
+
+		switch {
+		case inLocal == inRemote && localValue == remoteValue:
+			// Both sides agree, nothing to do
+		case !remoteChanged:
+			result.LocalChanges.record(key, localValue, inLocal, remoteValue, inRemote)
+		case !localChanged:
+			result.RemoteChanges.record(key, remoteValue, inRemote, localValue, inLocal)
+		default:
+			result.Conflicts = append(result.Conflicts, EnvironmentConflict{
+				Key:           key,
+				BaseValue:     baseValue,
+				LocalValue:    localValue,
+				RemoteValue:   remoteValue,
+				InBase:        inBase,
+				LocalDeleted:  !inLocal,
+				RemoteDeleted: !inRemote,
+			})
+		}
+	}
+
+	return result
+}
+
+// record adds the change of key on one side to the change set, given the
+// value on the changed side and the value still held by the other side
+func (cs *ChangeSet) record(key, value string, present bool, otherValue string, inOther bool) {
+	switch {
+	case !present:
+		cs.Deleted = append(cs.Deleted, EnvironmentVariable{Key: key, Value: otherValue})
+	case inOther:
+		cs.Updated = append(cs.Updated, EnvironmentVariable{Key: key, Value: value})
+	default:
+		cs.Added = append(cs.Added, EnvironmentVariable{Key: key, Value: value})
+	}
+}
+
+// Apply returns a copy of env with the change set applied
+func (cs ChangeSet) Apply(env map[string]string) map[string]string {
+	result := maps.Clone(env)
+	if result == nil {
+		result = make(map[string]string)
+	}
+	for _, v := range cs.Added {
+		result[v.Key] = v.Value
+	}
+	for _, v := range cs.Updated {
+		result[v.Key] = v.Value
+	}
+	for _, v := range cs.Deleted {
+		delete(result, v.Key)
+	}
+	return result
+}
+
+// ReconcileBase computes the merge base to persist after a sync. Keys on which
+// local and remote agree take the agreed value, all other keys keep their
+// previous base so the pending change is still attributed to the right side.
+func ReconcileBase(base, local, remote map[string]string) map[string]string {
+	result := make(map[string]string, len(local))
+
+	for key, localValue := range local {
+		if remoteValue, ok := remote[key]; ok && remoteValue == localValue {
+			result[key] = localValue
+		} else if baseValue, ok := base[key]; ok {
+			result[key] = baseValue
+		}
+	}
+
+	for key := range remote {
+		if _, ok := local[key]; ok {
+			continue
+		}
+		if baseValue, ok := base[key]; ok {
+			result[key] = baseValue
+		}
+	}
+
+	return result
+}
+
 // HasChanges returns true if there are any differences between local and remote
 func (es *EnvironmentSync) HasChanges() bool {
 	return len(es.ToAdd) > 0 || len(es.ToUpdate) > 0 || len(es.ToDelete) > 0
@@ -0,0 +1,216 @@
+package domain
+
+import (
+	"maps"
+	"slices"
+	"testing"
+)
+
+func keysOf(vars []EnvironmentVariable) []string {
+	keys := make([]string, len(vars))
+	for i, v := range vars {
+		keys[i] = v.Key
+	}
+	return keys
+}
+
+func TestEnvironmentSyncMerge(t *testing.T) {
+	tests := []struct {
+		name          string
+		base          map[string]string
+		local         map[string]string
+		remote        map[string]string
+		filter        KeyFilter
+		localAdded    []string
+		localUpdated  []string
+		localDeleted  []string
+		remoteAdded   []string
+		remoteUpdated []string
+		remoteDeleted []string
+		conflicts     []string
+	}{
+		{
+			name:   "no changes",
+			base:   map[string]string{"A": "1"},
+			local:  map[string]string{"A": "1"},
+			remote: map[string]string{"A": "1"},
+		},
+		{
+			name:         "local only changes",
+			base:         map[string]string{"A": "1", "B": "2", "C": "3"},
+			local:        map[string]string{"A": "10", "C": "3", "D": "4"},
+			remote:       map[string]string{"A": "1", "B": "2", "C": "3"},
+			localAdded:   []string{"D"},
+			localUpdated: []string{"A"},
+			localDeleted: []string{"B"},
+		},
+		{
+			name:          "remote only changes",
+			base:          map[string]string{"A": "1", "B": "2"},
+			local:         map[string]string{"A": "1", "B": "2"},
+			remote:        map[string]string{"A": "10", "E": "5"},
+			remoteAdded:   []string{"E"},
+			remoteUpdated: []string{"A"},
+			remoteDeleted: []string{"B"},
+		},
+		{
+			name:          "changes on different keys merge cleanly",
+			base:          map[string]string{"A": "1", "B": "2"},
+			local:         map[string]string{"A": "10", "B": "2"},
+			remote:        map[string]string{"A": "1", "B": "20"},
+			localUpdated:  []string{"A"},
+			remoteUpdated: []string{"B"},
+		},
+		{
+			name:   "same change on both sides",
+			base:   map[string]string{"A": "1"},
+			local:  map[string]string{"A": "2"},
+			remote: map[string]string{"A": "2"},
+		},
+		{
+			name:      "different changes on both sides",
+			base:      map[string]string{"A": "1", "B": "2"},
+			local:     map[string]string{"A": "10"},
+			remote:    map[string]string{"A": "11", "B": "20"},
+			conflicts: []string{"A", "B"},
+		},
+		{
+			name:        "first sync without base",
+			local:       map[string]string{"A": "1", "B": "2"},
+			remote:      map[string]string{"A": "1", "B": "3", "C": "4"},
+			remoteAdded: []string{"C"},
+			conflicts:   []string{"B"},
+		},
+		{
+			name:         "filtered keys are never changed",
+			base:         map[string]string{"DB_HOST": "a", "LOCAL_DB": "x"},
+			local:        map[string]string{"DB_HOST": "b", "DB_PORT": "1", "LOCAL_DB": "y", "APP": "1"},
+			remote:       map[string]string{"DB_HOST": "a", "LOCAL_DB": "z"},
+			filter:       KeyFilter{Include: []string{"DB_*", "LOCAL_*"}, Exclude: []string{"LOCAL_*"}},
+			localAdded:   []string{"DB_PORT"},
+			localUpdated: []string{"DB_HOST"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			remote := make(map[string]EnvironmentVariable)
+			for k, v := range tt.remote {
+				remote[k] = EnvironmentVariable{Key: k, Value: v}
+			}
+			es := NewEnvironmentSync(tt.local, remote)
+			es.Base = tt.base
+			es.Filter = tt.filter
+
+			result := es.Merge()
+
+			checks := []struct {
+				label    string
+				got      []string
+				expected []string
+			}{
+				{"local added", keysOf(result.LocalChanges.Added), tt.localAdded},
+				{"local updated", keysOf(result.LocalChanges.Updated), tt.localUpdated},
+				{"local deleted", keysOf(result.LocalChanges.Deleted), tt.localDeleted},
+				{"remote added", keysOf(result.RemoteChanges.Added), tt.remoteAdded},
+				{"remote updated", keysOf(result.RemoteChanges.Updated), tt.remoteUpdated},
+				{"remote deleted", keysOf(result.RemoteChanges.Deleted), tt.remoteDeleted},
+			}
+			for _, c := range checks {
+				if !slices.Equal(c.got, c.expected) {
+					t.Errorf("%s: expected %v, got %v", c.label, c.expected, c.got)
+				}
+			}
+
+			var conflicts []string
+			for _, c := range result.Conflicts {
+				conflicts = append(conflicts, c.Key)
+			}
+			if !slices.Equal(conflicts, tt.conflicts) {
+				t.Errorf("conflicts: expected %v, got %v", tt.conflicts, conflicts)
+			}
+		})
+	}
+}
+
+func TestReconcileBase(t *testing.T) {
+	base := map[string]string{"A": "1", "B": "2", "C": "3"}
+	local := map[string]string{"A": "1", "B": "20", "D": "4"}
+	remote := map[string]string{"A": "1", "B": "2", "D": "4", "E": "5"}
+
+	result := ReconcileBase(base, local, remote)
+	expected := map[string]string{
+		"A": "1", // agreed on both sides
+		"B": "2", // pending local change keeps the old base
+		"D": "4", // added on both sides with the same value
+	}
+
+	if !maps.Equal(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestSyncConfigTargets(t *testing.T) {
+	envTypes := []EnvType{
+		{ID: "et-dev", Name: "DEV"},
+		{ID: "et-prod", Name: "PROD"},
+	}
+
+	tests := []struct {
+		name      string
+		cfg       SyncConfig
+		expected  []SyncTarget
+		expectErr bool
+	}{
+		{
+			name:     "single env file",
+			cfg:      SyncConfig{EnvTypeID: "et-dev", EnvFile: ".env.local"},
+			expected: []SyncTarget{{EnvFile: ".env.local", EnvType: envTypes[0]}},
+		},
+		{
+			name: "file mappings by name and ID",
+			cfg: SyncConfig{
+				EnvTypeID: "et-dev",
+				Files: []EnvFileMapping{
+					{Path: ".env.development", EnvType: "dev"},
+					{Path: ".env.production", EnvType: "et-prod"},
+				},
+			},
+			expected: []SyncTarget{
+				{EnvFile: ".env.development", EnvType: envTypes[0]},
+				{EnvFile: ".env.production", EnvType: envTypes[1]},
+			},
+		},
+		{
+			name:      "unknown environment type",
+			cfg:       SyncConfig{Files: []EnvFileMapping{{Path: ".env", EnvType: "STAGING"}}},
+			expectErr: true,
+		},
+		{
+			name: "environment type mapped twice",
+			cfg: SyncConfig{Files: []EnvFileMapping{
+				{Path: ".env", EnvType: "DEV"},
+				{Path: ".env.dev", EnvType: "et-dev"},
+			}},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			targets, err := tt.cfg.Targets(envTypes)
+			if tt.expectErr {
+				if err == nil {
+					t.Fatalf("Expected an error, got %v", targets)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !slices.Equal(targets, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, targets)
+			}
+		})
+	}
+}
@@ -0,0 +1,265 @@
+// Package dotenv reads and writes .env files without losing their layout.
+// Comments, blank lines, ordering, export prefixes and quoting are kept as
+// they are, and only the assignments whose value changes are rewritten.
+package dotenv
+
+import (
+	"errors"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Quote is the quoting style of a value
+type Quote int
+
+const (
+	QuoteNone Quote = iota
+	QuoteSingle
+	QuoteDouble
+)
+
+// Node is a single entry of a .env file: an assignment, a comment or a blank line
+type Node struct {
+	// Key is empty for comments and blank lines
+	Key       string
+	Value     string
+	Export    bool
+	Quote     Quote
+	Multiline bool
+
+	raw     string // original text without the line break
+	prefix  string // indentation, export prefix, key and separator
+	suffix  string // whitespace and inline comment after the value
+	newline string
+	dirty   bool
+}
+
+// IsAssignment returns true if the node assigns a value to a key
+func (n *Node) IsAssignment() bool {
+	return n.Key != ""
+}
+
+func (n *Node) String() string {
+	if !n.dirty {
+		return n.raw + n.newline
+	}
+	return n.prefix + n.encodeValue() + n.suffix + n.newline
+}
+
+func (n *Node) setValue(value string) {
+	n.Value = value
+	n.dirty = true
+}
+
+// encodeValue renders the value in the quoting style of the node, switching to
+// double quotes when the original style cannot represent the new value
+func (n *Node) encodeValue() string {
+	v := n.Value
+
+	switch n.Quote {
+	case QuoteNone:
+		if canBeUnquoted(v) {
+			return v
+		}
+	case QuoteSingle:
+		if canBeSingleQuoted(v) {
+			return "'" + v + "'"
+		}
+	}
+
+	// A trailing backslash would escape the closing quote
+	if strings.HasSuffix(v, `\`) && canBeUnquoted(v) {
+		return v
+	}
+
+	return `"` + escapeDoubleQuoted(v, n.Multiline) + `"`
+}
+
+// Document is a parsed .env file
+type Document struct {
+	nodes []*Node
+}
+
+// ReadFile parses the .env file at path. A missing file results in an empty document.
+func ReadFile(path string) (*Document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &Document{}, nil
+		}
+		return nil, err
+	}
+
+	return Parse(data)
+}
+
+// WriteFile writes the document to path, keeping the permissions of an existing file
+func (d *Document) WriteFile(path string) error {
+	perm := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	return os.WriteFile(path, d.Bytes(), perm)
+}
+
+// Nodes returns the nodes of the document in file order
+func (d *Document) Nodes() []*Node {
+	return d.nodes
+}
+
+// Map returns the variables of the document. Like godotenv, the last
+// assignment of a key wins.
+func (d *Document) Map() map[string]string {
+	env := make(map[string]string)
+	for _, n := range d.nodes {
+		if n.IsAssignment() {
+			env[n.Key] = n.Value
+		}
+	}
+	return env
+}
+
+// Get returns the value of key
+func (d *Document) Get(key string) (string, bool) {
+	if n := d.lookup(key); n != nil {
+		return n.Value, true
+	}
+	return "", false
+}
+
+// Set updates the value of key in place, or appends a new assignment at the
+// end of the document. Nothing changes when the value is the same.
+func (d *Document) Set(key, value string) {
+	if n := d.lookup(key); n != nil {
+		if n.Value != value {
+			n.setValue(value)
+		}
+		return
+	}
+
+	newline := d.newline()
+	if len(d.nodes) > 0 {
+		if last := d.nodes[len(d.nodes)-1]; last.newline == "" {
+			last.newline = newline
+		}
+	}
+
+	quote := QuoteDouble
+	if _, err := strconv.Atoi(value); err == nil {
+		quote = QuoteNone
+	}
+
+	d.nodes = append(d.nodes, &Node{
+		Key:     key,
+		Value:   value,
+		Quote:   quote,
+		prefix:  key + "=",
+		newline: newline,
+		dirty:   true,
+	})
+}
+
+// Delete removes every assignment of key
+func (d *Document) Delete(key string) {
+	d.nodes = slices.DeleteFunc(d.nodes, func(n *Node) bool {
+		return n.Key == key
+	})
+}
+
+// Update makes the document hold exactly the variables in env. Changed keys
+// are rewritten in place, removed keys are dropped and new keys are appended
+// in alphabetical order.
+func (d *Document) Update(env map[string]string) {
+	for key := range d.Map() {
+		if _, ok := env[key]; !ok {
+			d.Delete(key)
+		}
+	}
+
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		d.Set(key, env[key])
+	}
+}
+
+// Bytes renders the document
+func (d *Document) Bytes() []byte {
+	var b strings.Builder
+	for _, n := range d.nodes {
+		b.WriteString(n.String())
+	}
+	return []byte(b.String())
+}
+
+// lookup returns the assignment that sets the effective value of key
+func (d *Document) lookup(key string) *Node {
+	for i := len(d.nodes) - 1; i >= 0; i-- {
+		if d.nodes[i].Key == key {
+			return d.nodes[i]
+		}
+	}
+	return nil
+}
+
+// newline returns the line break used by the document
+func (d *Document) newline() string {
+	for _, n := range d.nodes {
+		if n.newline != "" {
+			return n.newline
+		}
+	}
+	return "\n"
+}
+
+func canBeUnquoted(v string) bool {
+	if v != strings.TrimFunc(v, isSpace) {
+		return false
+	}
+	if strings.ContainsAny(v, "\n\r$") || strings.HasPrefix(v, `"`) || strings.HasPrefix(v, "'") {
+		return false
+	}
+	// A hash after whitespace would start an inline comment
+	for i := 1; i < len(v); i++ {
+		if v[i] == '#' && isSpace(rune(v[i-1])) {
+			return false
+		}
+	}
+	return true
+}
+
+func canBeSingleQuoted(v string) bool {
+	return !strings.Contains(v, "'") && !strings.HasSuffix(v, `\`)
+}
+
+func escapeDoubleQuoted(v string, multiline bool) string {
+	var b strings.Builder
+	for _, r := range v {
+		switch r {
+		case '\\':
+			b.WriteString(`\\`)
+		case '"':
+			b.WriteString(`\"`)
+		case '$':
+			b.WriteString(`\$`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\n':
+			if multiline {
+				b.WriteRune(r)
+			} else {
+				b.WriteString(`\n`)
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,168 @@
+package dotenv
+
+import (
+	"maps"
+	"testing"
+)
+
+const sample = `# Database settings
+export DB_HOST=localhost   # local only
+DB_PORT = 5432
+DB_USER='admin'
+
+# Multiline certificate
+CERT="-----BEGIN-----
+abc
+-----END-----"
+GREETING="hello\nworld"
+URL=http://${DB_HOST}:${DB_PORT}
+EMPTY=
+`
+
+func TestParseValues(t *testing.T) {
+	doc, err := Parse([]byte(sample))
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+
+	expected := map[string]string{
+		"DB_HOST":  "localhost",
+		"DB_PORT":  "5432",
+		"DB_USER":  "admin",
+		"CERT":     "-----BEGIN-----\nabc\n-----END-----",
+		"GREETING": "hello\nworld",
+		"URL":      "http://localhost:5432",
+		"EMPTY":    "",
+	}
+	if got := doc.Map(); !maps.Equal(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestRoundTripUnchanged(t *testing.T) {
+	inputs := []string{
+		sample,
+		"A=1",
+		"A=1\r\nB=\"x\r\ny\"\r\n",
+		"\n\n# only comments\n",
+	}
+
+	for _, input := range inputs {
+		doc, err := Parse([]byte(input))
+		if err != nil {
+			t.Fatalf("Parse(%q) returned error: %v", input, err)
+		}
+
+		doc.Update(doc.Map())
+		if got := string(doc.Bytes()); got != input {
+			t.Errorf("Expected round trip to keep %q, got %q", input, got)
+		}
+	}
+}
+
+func TestUpdateOnlyTouchesChangedLines(t *testing.T) {
+	doc, err := Parse([]byte(sample))
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+
+	env := doc.Map()
+	env["DB_HOST"] = "db.internal"
+	env["DB_USER"] = "root"
+	env["CERT"] = "-----BEGIN-----\nxyz\n-----END-----"
+	env["NEW_PORT"] = "8080"
+	env["NEW_NAME"] = "my app"
+	delete(env, "GREETING")
+
+	doc.Update(env)
+
+	expected := `# Database settings
+export DB_HOST=db.internal   # local only
+DB_PORT = 5432
+DB_USER='root'
+
+# Multiline certificate
+CERT="-----BEGIN-----
+xyz
+-----END-----"
+URL=http://${DB_HOST}:${DB_PORT}
+EMPTY=
+NEW_NAME="my app"
+NEW_PORT=8080
+`
+	if got := string(doc.Bytes()); got != expected {
+		t.Errorf("Unexpected output:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+func TestEncodedValuesParseBack(t *testing.T) {
+	values := []string{
+		`plain`,
+		`with space `,
+		`quote " and 'single'`,
+		`dollar $HOME and ${PATH}`,
+		`backslash \n literal`,
+		`trailing backslash\`,
+		"line\nbreak",
+		`hash # comment`,
+	}
+
+	for _, quote := range []string{"A=x\n", "A='x'\n", "A=\"x\"\n"} {
+		for _, value := range values {
+			doc, err := Parse([]byte(quote))
+			if err != nil {
+				t.Fatalf("Parse returned error: %v", err)
+			}
+			doc.Set("A", value)
+
+			parsed, err := Parse(doc.Bytes())
+			if err != nil {
+				t.Fatalf("Parse(%q) returned error: %v", doc.Bytes(), err)
+			}
+			if got, _ := parsed.Get("A"); got != value {
+				t.Errorf("Expected %q to survive a round trip from %q, got %q (written as %q)", value, quote, got, doc.Bytes())
+			}
+		}
+	}
+}
+
+func TestBackslashesBeforeQuotesRoundTrip(t *testing.T) {
+	values := []string{
+		` a\`,
+		` a\\`,
+		`say \"hi\"`,
+		`ends with \"`,
+		`\\" mixed \\\"`,
+	}
+
+	for _, value := range values {
+		doc := &Document{}
+		doc.Set("A", value)
+		doc.Set("B", "after")
+
+		parsed, err := Parse(doc.Bytes())
+		if err != nil {
+			t.Fatalf("Parse(%q) returned error: %v", doc.Bytes(), err)
+		}
+		if got, _ := parsed.Get("A"); got != value {
+			t.Errorf("Expected %q, got %q (written as %q)", value, got, doc.Bytes())
+		}
+		if got, _ := parsed.Get("B"); got != "after" {
+			t.Errorf("Expected the next line to parse after %q, got %q", doc.Bytes(), got)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	inputs := []string{
+		"A=\"unterminated\n",
+		"NOT AN ASSIGNMENT\n",
+		"-A=1\n",
+	}
+
+	for _, input := range inputs {
+		if _, err := Parse([]byte(input)); err == nil {
+			t.Errorf("Expected an error for %q", input)
+		}
+	}
+}
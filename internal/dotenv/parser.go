@@ -0,0 +1,216 @@
+package dotenv
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+const exportPrefix = "export"
+
+// Parse reads a .env file into a Document. Values are decoded the same way
+// godotenv does it: double quoted values have their escapes and variables
+// expanded, unquoted values have their variables expanded and single quoted
+// values are taken literally.
+func Parse(src []byte) (*Document, error) {
+	doc := &Document{}
+	vars := make(map[string]string)
+
+	text := string(src)
+	line := 1
+	for len(text) > 0 {
+		n, consumed, err := parseNode(text, vars)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		doc.nodes = append(doc.nodes, n)
+		if n.Key != "" {
+			vars[n.Key] = n.Value
+		}
+
+		line += strings.Count(text[:consumed], "\n")
+		text = text[consumed:]
+	}
+
+	return doc, nil
+}
+
+// parseNode parses the node at the start of text and returns the number of
+// bytes it spans, trailing line break included
+func parseNode(text string, vars map[string]string) (*Node, int, error) {
+	end := lineEnd(text, 0)
+	trimmed := strings.TrimSpace(text[:end])
+
+	if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+		n := &Node{raw: text[:end], newline: lineBreak(text, end)}
+		return n, end + len(n.newline), nil
+	}
+
+	n := &Node{}
+
+	// Indentation, optional export prefix, key and separator
+	pos := len(text[:end]) - len(strings.TrimLeftFunc(text[:end], isSpace))
+	if rest := text[pos:end]; strings.HasPrefix(rest, exportPrefix) {
+		after := rest[len(exportPrefix):]
+		if len(after) > 0 && isSpace(rune(after[0])) {
+			n.Export = true
+			pos += len(exportPrefix) + len(after) - len(strings.TrimLeftFunc(after, isSpace))
+		}
+	}
+
+	keyStart := pos
+	for pos < end && isKeyChar(rune(text[pos])) {
+		pos++
+	}
+	n.Key = text[keyStart:pos]
+	if n.Key == "" {
+		return nil, 0, fmt.Errorf("unexpected character %q in variable name", text[pos])
+	}
+
+	pos = skipSpace(text, pos, end)
+	if pos >= end || (text[pos] != '=' && text[pos] != ':') {
+		return nil, 0, fmt.Errorf("missing '=' after variable name %q", n.Key)
+	}
+	pos = skipSpace(text, pos+1, end)
+	n.prefix = text[:pos]
+
+	// Value
+	switch {
+	case pos < len(text) && (text[pos] == '"' || text[pos] == '\''):
+		quote := text[pos]
+		closing := closingQuote(text, pos+1, quote)
+		if closing == -1 {
+			return nil, 0, fmt.Errorf("unterminated quoted value for %q", n.Key)
+		}
+
+		value := strings.ReplaceAll(text[pos+1:closing], "\r\n", "\n")
+		n.Multiline = strings.Contains(value, "\n")
+		if quote == '"' {
+			n.Quote = QuoteDouble
+			n.Value = expandVariables(expandEscapes(value), vars)
+		} else {
+			n.Quote = QuoteSingle
+			n.Value = value
+		}
+
+		end = lineEnd(text, closing+1)
+		n.suffix = text[closing+1 : end]
+	default:
+		value := text[pos:end]
+		suffixStart := len(value)
+		// An inline comment needs whitespace in front of the hash
+		for i := len(value) - 1; i > 0; i-- {
+			if value[i] == '#' && isSpace(rune(value[i-1])) {
+				suffixStart = i
+				break
+			}
+		}
+		value = strings.TrimRightFunc(value[:suffixStart], isSpace)
+
+		n.Quote = QuoteNone
+		n.Value = expandVariables(value, vars)
+		n.suffix = text[pos+len(value) : end]
+	}
+
+	n.raw = text[:end]
+	n.newline = lineBreak(text, end)
+	return n, end + len(n.newline), nil
+}
+
+// lineEnd returns the index of the line break after from, or the end of text
+func lineEnd(text string, from int) int {
+	if i := strings.IndexByte(text[from:], '\n'); i != -1 {
+		end := from + i
+		if end > from && text[end-1] == '\r' {
+			end--
+		}
+		return end
+	}
+	return len(text)
+}
+
+func lineBreak(text string, end int) string {
+	switch {
+	case strings.HasPrefix(text[end:], "\r\n"):
+		return "\r\n"
+	case strings.HasPrefix(text[end:], "\n"):
+		return "\n"
+	default:
+		return ""
+	}
+}
+
+// closingQuote finds the quote that ends a value, skipping escaped quotes.
+// A quote is escaped by an odd number of backslashes, an even number are
+// escaped backslashes themselves.
+func closingQuote(text string, from int, quote byte) int {
+	backslashes := 0
+	for i := from; i < len(text); i++ {
+		switch {
+		case text[i] == '\\':
+			backslashes++
+			continue
+		case text[i] == quote && backslashes%2 == 0:
+			return i
+		}
+		backslashes = 0
+	}
+	return -1
+}
+
+func skipSpace(text string, pos, end int) int {
+	for pos < end && isSpace(rune(text[pos])) {
+		pos++
+	}
+	return pos
+}
+
+func isKeyChar(r rune) bool {
+	return r == '_' || r == '.' || unicode.IsLetter(r) || unicode.IsNumber(r)
+}
+
+// isSpace reports whether the rune is a space character but not a line break
+func isSpace(r rune) bool {
+	switch r {
+	case '\t', '\v', '\f', '\r', ' ', 0x85, 0xA0:
+		return true
+	}
+	return false
+}
+
+var (
+	escapeRegex        = regexp.MustCompile(`\\.`)
+	expandVarRegex     = regexp.MustCompile(`(\\)?(\$)(\()?\{?([A-Z0-9_]+)?\}?`)
+	unescapeCharsRegex = regexp.MustCompile(`\\([^$])`)
+)
+
+func expandEscapes(str string) string {
+	out := escapeRegex.ReplaceAllStringFunc(str, func(match string) string {
+		switch strings.TrimPrefix(match, `\`) {
+		case "n":
+			return "\n"
+		case "r":
+			return "\r"
+		default:
+			return match
+		}
+	})
+	return unescapeCharsRegex.ReplaceAllString(out, "$1")
+}
+
+func expandVariables(v string, vars map[string]string) string {
+	return expandVarRegex.ReplaceAllStringFunc(v, func(s string) string {
+		submatch := expandVarRegex.FindStringSubmatch(s)
+		if submatch == nil {
+			return s
+		}
+		if submatch[1] == "\\" || submatch[2] == "(" {
+			return submatch[0][1:]
+		} else if submatch[4] != "" {
+			return vars[submatch[4]]
+		}
+		return s
+	})
+}
@@ -14,6 +14,7 @@ func EnvironmentCommands(handlers *handlers.EnvironmentHandler) *cli.Command {
 			SwitchEnvironmentCommand(handlers),
 			GetAllEnvironmentsCommand(handlers),
 			DeleteEnvironmentCommand(handlers),
+			PromoteEnvironmentCommand(handlers),
 		},
 	}
 }
@@ -70,3 +71,64 @@ func DeleteEnvironmentCommand(handlers *handlers.EnvironmentHandler) *cli.Comman
 		},
 	}
 }
+
+func PromoteEnvironmentCommand(handlers *handlers.EnvironmentHandler) *cli.Command {
+	return &cli.Command{
+		Name:   "promote",
+		Usage:  "Copy variables from one environment type to another",
+		Action: handlers.PromoteEnvironment,
+		Description: `Create or update the variables of the --from environment type in the
+--to environment type of the configured app. Variables that only exist in the
+target are never deleted. The plan is shown for confirmation before anything
+is written, unless --yes is given.
+
+Examples:
+  envsync env promote --from STAGING --to PROD --dry-run
+  envsync env promote --from STAGING --to PROD --keys 'STRIPE_*' --keys API_URL
+  envsync env promote --from DEV --to STAGING --skip-existing`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "config",
+				DefaultText: "envsyncrc.toml",
+				Required:    false,
+				Usage:       "Path to the configuration file",
+				Value:       "envsyncrc.toml",
+			},
+			&cli.StringFlag{
+				Name:     "from",
+				Usage:    "Environment type to copy the variables from, by name or ID",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:     "to",
+				Usage:    "Environment type to copy the variables to, by name or ID",
+				Required: true,
+			},
+			&cli.StringSliceFlag{
+				Name:  "keys",
+				Usage: "Only promote these keys, globs like 'DB_*' are allowed",
+			},
+			&cli.BoolFlag{
+				Name:  "skip-existing",
+				Usage: "Leave keys that already exist in the target untouched",
+				Value: false,
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Show the plan without applying it",
+				Value: false,
+			},
+			&cli.BoolFlag{
+				Name:  "confirm-protected",
+				Usage: "Allow promoting to a protected environment type without prompting",
+				Value: false,
+			},
+			&cli.BoolFlag{
+				Name:    "yes",
+				Aliases: []string{"y"},
+				Usage:   "Promote without prompting for confirmation",
+				Value:   false,
+			},
+		},
+	}
+}
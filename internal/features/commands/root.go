@@ -76,6 +76,8 @@ func (r *CommandRegistry) RegisterCLI() *cli.Command {
 			EnvironmentCommands(r.environmentHandler),
 			PullCommand(r.syncHandler),
 			PushCommand(r.syncHandler),
+			RestoreCommand(r.syncHandler),
+			DiffCommand(r.syncHandler),
 			InitCommand(r.initHandler),
 			RunCommand(r.runHandler),
 			GenereatePrivateKeyCommand(r.genPEMKeyHandler),
@@ -2,20 +2,69 @@ package commands
 
 import (
 	"github.com/EnvSync-Cloud/envsync-cli/internal/features/handlers"
+	"github.com/EnvSync-Cloud/envsync-cli/internal/features/usecases/run"
 	"github.com/urfave/cli/v3"
 )
 
 func RunCommand(handler *handlers.RunHandler) *cli.Command {
 	return &cli.Command{
-		Name:   "run",
-		Usage:  "Run application with environment variables",
-		Action: handler.Run,
+		Name:      "run",
+		Usage:     "Run application with environment variables",
+		Action:    handler.Run,
+		ArgsUsage: "-- <command> [args...]",
+		Description: `Run a command with the remote environment variables and secrets of the
+project, their values hidden in its output. See docs/run.md for redaction,
+signals, processes and exit codes.
+
+Examples:
+  envsync run -- npm run dev --port 3000
+  envsync run --shell -- 'npm run build && npm start'
+  envsync run --procfile Procfile`,
+		// --env values are taken whole, commas included
+		DisableSliceFlagSeparator: true,
 		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "shell",
+				Usage: "Run the command through $SHELL -c",
+				Value: false,
+			},
+			&cli.StringFlag{
+				Name:  "redact-mode",
+				Usage: "How values are found in the output: word, exact or entropy",
+			},
+			&cli.StringFlag{
+				Name:  "redaction-report",
+				Usage: "Write the keys whose values were redacted, with counts per stream, to a JSON file when the command exits",
+			},
+			&cli.DurationFlag{
+				Name:  "grace-period",
+				Usage: "How long the command gets to exit after SIGINT, SIGTERM, SIGHUP or SIGQUIT before it is killed",
+				Value: run.DefaultKillGracePeriod,
+			},
+			&cli.StringFlag{
+				Name:  "procfile",
+				Usage: "Run the processes of a Procfile together instead of a single command",
+			},
+			&cli.BoolFlag{
+				Name:  "watch",
+				Usage: "Restart the command when the remote variables or secrets change",
+				Value: false,
+			},
+			&cli.DurationFlag{
+				Name:  "watch-interval",
+				Usage: "How often --watch checks the remote variables and secrets",
+				Value: run.DefaultWatchInterval,
+			},
+			&cli.BoolFlag{
+				Name:  "no-tty",
+				Usage: "Run without a pty, with separate stdout and stderr (the default when not in a terminal)",
+				Value: false,
+			},
 			&cli.StringFlag{
 				Name:     "command",
-				Usage:    "Command to run the application",
+				Usage:    "Command line to run through the shell (deprecated, pass the command after -- instead)",
 				Aliases:  []string{"c"},
-				Required: true,
+				Required: false,
 			},
 			&cli.StringFlag{
 				Name:     "private-key",
@@ -23,6 +72,40 @@ func RunCommand(handler *handlers.RunHandler) *cli.Command {
 				Aliases:  []string{"pk"},
 				Required: false,
 			},
+			&cli.StringFlag{
+				Name:        "config",
+				DefaultText: "envsyncrc.toml",
+				Required:    false,
+				Usage:       "Path to the configuration file",
+				Value:       "envsyncrc.toml",
+			},
+			&cli.StringSliceFlag{
+				Name:  "env",
+				Usage: "Set KEY=VALUE in the environment of the command, overriding remote variables and secrets",
+			},
+			&cli.StringSliceFlag{
+				Name:  "file-secret",
+				Usage: "Write the secret KEY to a private file and set KEY to its path, as KEY or KEY=NAME",
+			},
+			&cli.BoolFlag{
+				Name:  "clean-env",
+				Usage: "Do not inherit the environment of the shell, except for an allowlist like PATH and HOME",
+				Value: false,
+			},
+			&cli.StringSliceFlag{
+				Name:  "keep-env",
+				Usage: "Inherit these variables from the shell as well with --clean-env",
+			},
+			&cli.BoolFlag{
+				Name:  "keep-term",
+				Usage: "Keep TERM from the shell instead of setting it to xterm-256color",
+				Value: false,
+			},
+			&cli.StringFlag{
+				Name:     "env-type",
+				Usage:    "Environment type to run with, by name or ID (defaults to env_type_id from the configuration)",
+				Required: false,
+			},
 		},
 	}
 }
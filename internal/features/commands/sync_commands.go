@@ -1,6 +1,8 @@
 package commands
 
 import (
+	"fmt"
+
 	"github.com/EnvSync-Cloud/envsync-cli/internal/features/handlers"
 	"github.com/urfave/cli/v3"
 )
@@ -18,6 +20,20 @@ func PullCommand(handler *handlers.SyncHandler) *cli.Command {
 				Usage:       "Path to the configuration file",
 				Value:       "envsyncrc.toml",
 			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Show the changes that would be made without applying them",
+				Value: false,
+			},
+			strategyFlag(),
+			&cli.StringSliceFlag{
+				Name:  "only",
+				Usage: "Only sync keys matching these globs, e.g. 'DB_*'",
+			},
+			&cli.StringSliceFlag{
+				Name:  "exclude",
+				Usage: "Leave keys matching these globs untouched, e.g. 'LOCAL_*'",
+			},
 		},
 	}
 }
@@ -35,6 +51,142 @@ func PushCommand(handler *handlers.SyncHandler) *cli.Command {
 				Usage:       "Path to the configuration file",
 				Value:       "envsyncrc.toml",
 			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Show the changes that would be made without applying them",
+				Value: false,
+			},
+			strategyFlag(),
+			&cli.StringSliceFlag{
+				Name:  "only",
+				Usage: "Only sync keys matching these globs, e.g. 'DB_*'",
+			},
+			&cli.StringSliceFlag{
+				Name:  "exclude",
+				Usage: "Leave keys matching these globs untouched, e.g. 'LOCAL_*'",
+			},
+			&cli.BoolFlag{
+				Name:  "confirm-protected",
+				Usage: "Allow pushing to a protected environment type without prompting",
+				Value: false,
+			},
+			&cli.BoolFlag{
+				Name:  "confirm-deletes",
+				Usage: "Allow deleting more remote variables than the configured delete_threshold",
+				Value: false,
+			},
+		},
+	}
+}
+
+func RestoreCommand(handler *handlers.SyncHandler) *cli.Command {
+	return &cli.Command{
+		Name:   "restore",
+		Usage:  "Restore a local env file from one of its backups",
+		Action: handler.Restore,
+		Description: `Every pull keeps a copy of the env file it overwrites in .envsync/backups.
+Restore puts the latest backup back in place, or the one picked with --at.
+The next pull or push then merges without a base, so keys that differ from
+the remote show up as conflicts instead of being pushed.
+
+Examples:
+  envsync restore --list
+  envsync restore --dry-run
+  envsync restore --file .env.production --at 20250102T150405`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "config",
+				DefaultText: "envsyncrc.toml",
+				Required:    false,
+				Usage:       "Path to the configuration file",
+				Value:       "envsyncrc.toml",
+			},
+			&cli.StringFlag{
+				Name:  "file",
+				Usage: "Env file to restore when several are configured",
+			},
+			&cli.BoolFlag{
+				Name:  "list",
+				Usage: "List the available backups",
+				Value: false,
+			},
+			&cli.StringFlag{
+				Name:  "at",
+				Usage: "Timestamp of the backup to restore, as shown by --list. A unique prefix is enough",
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Show the changes that would be made without applying them",
+				Value: false,
+			},
+			&cli.BoolFlag{
+				Name:    "yes",
+				Aliases: []string{"y"},
+				Usage:   "Restore without prompting for confirmation",
+				Value:   false,
+			},
+		},
+	}
+}
+
+func DiffCommand(handler *handlers.SyncHandler) *cli.Command {
+	return &cli.Command{
+		Name:   "diff",
+		Usage:  "Show the differences between local and remote environment variables",
+		Action: handler.Diff,
+		Description: `Compare the local env files with the remote without syncing anything.
+Pass --env twice to compare two environment types of the app, or --app to
+compare the same environment type across apps. Values are masked unless
+--show-values is given.
+
+Examples:
+  envsync diff
+  envsync diff --env DEV --env PROD
+  envsync diff --app billing --env PROD
+  envsync diff --app billing --app payments --json`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "config",
+				DefaultText: "envsyncrc.toml",
+				Required:    false,
+				Usage:       "Path to the configuration file",
+				Value:       "envsyncrc.toml",
+			},
+			&cli.StringSliceFlag{
+				Name:  "env",
+				Usage: "Environment type to compare, by name or ID. Give it twice to compare two environment types",
+			},
+			&cli.StringSliceFlag{
+				Name:  "app",
+				Usage: "App to compare, by name or ID. A single app is compared with the configured one",
+			},
+			&cli.BoolFlag{
+				Name:  "show-values",
+				Usage: "Show the values instead of masking them",
+				Value: false,
+			},
+			&cli.StringSliceFlag{
+				Name:  "only",
+				Usage: "Only compare keys matching these globs, e.g. 'DB_*'",
+			},
+			&cli.StringSliceFlag{
+				Name:  "exclude",
+				Usage: "Skip keys matching these globs, e.g. 'LOCAL_*'",
+			},
+		},
+	}
+}
+
+func strategyFlag() *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:  "strategy",
+		Usage: "Resolve conflicts without prompting: ours (keep local), theirs (take remote) or fail",
+		Validator: func(s string) error {
+			switch s {
+			case "", "ours", "theirs", "fail":
+				return nil
+			}
+			return fmt.Errorf("invalid strategy %q, expected ours, theirs or fail", s)
 		},
 	}
 }
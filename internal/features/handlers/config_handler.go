@@ -200,7 +200,7 @@ func (h *ConfigHandler) extractKeyValuePairs(args cli.Args) (map[string]string,
 		arg := args.Get(i)
 		parts := strings.SplitN(arg, "=", 2)
 		if len(parts) != 2 {
-			errors.New("Invalid format: '" + arg + "'. Expected format: key=value")
+			return nil, errors.New("Invalid format: '" + arg + "'. Expected format: key=value")
 		}
 		key := strings.TrimSpace(parts[0])
 		value := strings.TrimSpace(parts[1])
@@ -3,6 +3,8 @@ package handlers
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/urfave/cli/v3"
@@ -10,12 +12,14 @@ import (
 	"github.com/EnvSync-Cloud/envsync-cli/internal/domain"
 	"github.com/EnvSync-Cloud/envsync-cli/internal/features/usecases/environment"
 	"github.com/EnvSync-Cloud/envsync-cli/internal/presentation/formatters"
+	"github.com/EnvSync-Cloud/envsync-cli/internal/utils"
 )
 
 type EnvironmentHandler struct {
 	getEnvUseCase    environment.GetEnvUseCase
 	switchEnvUseCase environment.SwitchEnvUseCase
 	deleteEnvUseCase environment.DeleteEnvUseCase
+	promoteUseCase   environment.PromoteEnvUseCase
 	formatter        *formatters.EnvFormatter
 }
 
@@ -23,12 +27,14 @@ func NewEnvironmentHandler(
 	getEnvUseCase environment.GetEnvUseCase,
 	switchEnvUseCase environment.SwitchEnvUseCase,
 	deleteEnvUseCase environment.DeleteEnvUseCase,
+	promoteUseCase environment.PromoteEnvUseCase,
 	formatter *formatters.EnvFormatter,
 ) *EnvironmentHandler {
 	return &EnvironmentHandler{
 		getEnvUseCase:    getEnvUseCase,
 		switchEnvUseCase: switchEnvUseCase,
 		deleteEnvUseCase: deleteEnvUseCase,
+		promoteUseCase:   promoteUseCase,
 		formatter:        formatter,
 	}
 }
@@ -91,6 +97,59 @@ func (h *EnvironmentHandler) DeleteEnvironment(ctx context.Context, cmd *cli.Com
 	return nil
 }
 
+func (h *EnvironmentHandler) PromoteEnvironment(ctx context.Context, cmd *cli.Command) error {
+	opts := environment.PromoteOptions{
+		ConfigPath:       cmd.String("config"),
+		From:             cmd.String("from"),
+		To:               cmd.String("to"),
+		Keys:             cmd.StringSlice("keys"),
+		SkipExisting:     cmd.Bool("skip-existing"),
+		DryRun:           cmd.Bool("dry-run"),
+		ConfirmProtected: cmd.Bool("confirm-protected"),
+		Confirmed:        cmd.Bool("yes"),
+		Interactive:      !cmd.Bool("json") && utils.IsInteractive(),
+	}
+
+	promotion, err := h.promoteUseCase.Execute(ctx, opts)
+	if err != nil {
+		if errors.Is(err, tea.ErrProgramKilled) {
+			fmt.Fprintln(cmd.Writer, "Promote cancelled.")
+			return nil
+		}
+		return h.formatUseCaseError(cmd, err)
+	}
+
+	var warnings []string
+	if len(promotion.Skipped) > 0 {
+		warnings = append(warnings, fmt.Sprintf("%d key(s) already in %s were skipped: %s",
+			len(promotion.Skipped), promotion.To, strings.Join(promotion.Skipped, ", ")))
+	}
+	if len(promotion.Missing) > 0 {
+		warnings = append(warnings, fmt.Sprintf("%d key(s) not found in %s: %s",
+			len(promotion.Missing), promotion.From, strings.Join(promotion.Missing, ", ")))
+	}
+
+	changes := domain.ChangeSet{Added: promotion.Added, Updated: promotion.Updated}
+	plan := formatters.NewPlan("promote", promotion.DryRun, changes, nil, warnings)
+	plan.EnvType = promotion.To
+
+	if cmd.Bool("json") {
+		return h.formatter.FormatJSON(cmd.Writer, plan)
+	}
+
+	target := promotion.From + " → " + promotion.To
+	if err := h.formatter.FormatPromotePlan(cmd.Writer, plan, target); err != nil {
+		return err
+	}
+
+	if !promotion.DryRun && promotion.HasChanges() {
+		fmt.Fprintf(cmd.Writer, "\nPromote (%s) completed with %d added and %d updated variables.\n",
+			target, len(promotion.Added), len(promotion.Updated))
+	}
+
+	return nil
+}
+
 func (h *EnvironmentHandler) formatUseCaseError(cmd *cli.Command, err error) error {
 	if cmd.Bool("json") {
 		// If JSON output is requested, format the error as JSON
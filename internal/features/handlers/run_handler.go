@@ -3,14 +3,23 @@ package handlers
 import (
 	"context"
 	"errors"
-	"strings"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
 
+	"github.com/EnvSync-Cloud/envsync-cli/internal/domain"
 	"github.com/EnvSync-Cloud/envsync-cli/internal/features/usecases/run"
+	"github.com/EnvSync-Cloud/envsync-cli/internal/services"
+	"github.com/EnvSync-Cloud/envsync-cli/internal/utils"
 	"github.com/urfave/cli/v3"
 )
 
 type RunHandler struct {
 	redactUseCase       run.RedactUseCase
+	runProcessesUseCase run.RunProcessesUseCase
+	watchUseCase        run.WatchUseCase
 	injectEnvUseCase    run.InjectEnvUseCase
 	injectSecretUseCase run.InjectSecretsUseCase
 	appUseCase          run.FetchAppUseCase
@@ -19,6 +28,8 @@ type RunHandler struct {
 
 func NewRunHandler(
 	ruc run.RedactUseCase,
+	rpuc run.RunProcessesUseCase,
+	wuc run.WatchUseCase,
 	iuc run.InjectEnvUseCase,
 	isuc run.InjectSecretsUseCase,
 	auc run.FetchAppUseCase,
@@ -26,6 +37,8 @@ func NewRunHandler(
 ) *RunHandler {
 	return &RunHandler{
 		redactUseCase:       ruc,
+		runProcessesUseCase: rpuc,
+		watchUseCase:        wuc,
 		injectEnvUseCase:    iuc,
 		injectSecretUseCase: isuc,
 		appUseCase:          auc,
@@ -33,45 +46,361 @@ func NewRunHandler(
 	}
 }
 
+// Run runs the command and exits with its exit status. Failures of envsync
+// itself exit with the codes documented in the run package.
 func (h *RunHandler) Run(ctx context.Context, cmd *cli.Command) error {
-	c := strings.Split(cmd.String("command"), " ")
+	if err := h.run(ctx, cmd); err != nil {
+		return runExitError(err)
+	}
+	return nil
+}
+
+func (h *RunHandler) run(ctx context.Context, cmd *cli.Command) error {
+	if cmd.Bool("watch") && cmd.Duration("watch-interval") <= 0 {
+		return run.NewConfigError("--watch-interval must be greater than zero", nil)
+	}
 
-	configData, err := h.readConfigUseCase.Execute(ctx)
+	configData, err := h.readConfigUseCase.Execute(ctx, cmd.String("config"), cmd.String("env-type"))
 	if err != nil {
 		return err
 	}
 
+	processes, err := h.processes(cmd, configData)
+	if err != nil {
+		return err
+	}
+
+	var c []string
+	if processes == nil {
+		c, err = h.commandArgs(cmd)
+		if err != nil {
+			return err
+		}
+	}
+
+	ctx = context.WithValue(ctx, "configPath", cmd.String("config"))
+	ctx = context.WithValue(ctx, "appID", configData.AppID)
+	ctx = context.WithValue(ctx, "envTypeID", configData.EnvTypeID)
+
 	app, err := h.appUseCase.Execute(ctx, configData.AppID)
 	if err != nil {
 		return err
 	}
 
-	envs, err := h.injectEnvUseCase.Execute(ctx)
+	overrides, err := run.ParseEnvOverrides(cmd.StringSlice("env"))
 	if err != nil {
 		return err
 	}
 
+	// --file-secret adds to and overrides the configured files
+	fileSecrets, err := run.ParseFileSecrets(cmd.StringSlice("file-secret"))
+	if err != nil {
+		return err
+	}
+	for key, name := range configData.FileSecrets {
+		if _, ok := fileSecrets[key]; !ok {
+			fileSecrets[key] = name
+		}
+	}
+
 	if app.EnableSecrets {
 		if !cmd.IsSet("private-key") && !app.IsManagedSecret {
-			return errors.New("private-key flag is required when secrets are enabled")
+			return run.NewConfigError("private-key flag is required when secrets are enabled", nil)
 		}
 
 		ctx = context.WithValue(ctx, "managedSecret", app.IsManagedSecret)
 		ctx = context.WithValue(ctx, "privateKeyPath", cmd.String("private-key"))
-		ctx = context.WithValue(ctx, "appID", configData.AppID)
-		ctx = context.WithValue(ctx, "envTypeID", configData.EnvTypeID)
+	}
+
+	snapshot, err := h.fetchSnapshot(ctx, app.EnableSecrets)
+	if err != nil {
+		return err
+	}
+
+	// A pty is only useful when envsync itself runs in a terminal
+	tty := !cmd.Bool("no-tty") && utils.IsInteractive()
+
+	// One report covers every run of the command with --watch
+	var report *services.RedactionReport
+	if cmd.IsSet("redaction-report") {
+		report = services.NewRedactionReport()
+	}
 
-		secrets, err := h.injectSecretUseCase.Execute(ctx)
+	execute := func(ctx context.Context, snapshot run.EnvSnapshot) (int, error) {
+		// The variables of file secrets hold the path, the value stays redacted
+		childSecrets := snapshot.Secrets
+		if len(fileSecrets) > 0 {
+			files, err := h.injectSecretUseCase.WriteFiles(snapshot.Secrets, fileSecrets)
+			if err != nil {
+				return 0, err
+			}
+			defer func() {
+				if err := files.Remove(); err != nil {
+					fmt.Fprintf(os.Stderr, "envsync: failed to remove secret files in %s: %v\n", files.Dir, err)
+				}
+			}()
+
+			childSecrets = maps.Clone(snapshot.Secrets)
+			maps.Copy(childSecrets, files.Paths)
+		}
+
+		childEnv := run.BuildEnv(os.Environ(), snapshot.Env, childSecrets, run.EnvOptions{
+			Clean:      cmd.Bool("clean-env"),
+			Allow:      slices.Concat(run.DefaultCleanEnvAllowlist, cmd.StringSlice("keep-env")),
+			ForceColor: tty,
+			KeepTerm:   cmd.Bool("keep-term"),
+			Overrides:  overrides,
+		})
+
+		// Remote values and secrets are hidden from the output, secrets last so they win
+		values := snapshot.Values()
+
+		redact, err := h.redactOptions(cmd, configData.Redact, values)
 		if err != nil {
-			return err
+			return 0, err
 		}
+		redact.Report = report
 
-		for key, value := range secrets {
-			envs[key] = value
+		if processes != nil {
+			return h.runProcessesUseCase.Execute(ctx, run.ProcessOptions{
+				Processes:   processes,
+				Env:         childEnv,
+				Values:      values,
+				Redact:      redact,
+				GracePeriod: cmd.Duration("grace-period"),
+			}), nil
 		}
+
+		return h.redactUseCase.Execute(ctx, run.ExecOptions{
+			Args:        c,
+			Env:         childEnv,
+			Values:      values,
+			Redact:      redact,
+			GracePeriod: cmd.Duration("grace-period"),
+			TTY:         tty,
+		}), nil
 	}
 
-	_ = h.redactUseCase.Execute(ctx, c, envs)
+	var code int
+	if cmd.Bool("watch") {
+		code, err = h.runWatched(ctx, cmd, snapshot, app.EnableSecrets, execute)
+	} else {
+		code, err = execute(ctx, snapshot)
+	}
+	if err != nil {
+		return err
+	}
+
+	if path := cmd.String("redaction-report"); path != "" {
+		if err := report.WriteFile(path, code); err != nil {
+			reportErr := run.NewSetupError("failed to write redaction report", err)
+			if code == 0 {
+				return reportErr
+			}
+			// The exit status of the command matters more than the report
+			fmt.Fprintf(os.Stderr, "envsync: %v\n", reportErr)
+		}
+	}
+
+	if code != 0 {
+		// The command reported its own failure, exit quietly with its status
+		return cli.Exit("", code)
+	}
 
 	return nil
 }
+
+// redactOptions resolves the redaction settings of the configuration for the
+// keys of values. --redact-mode takes precedence over the configured mode.
+func (h *RunHandler) redactOptions(cmd *cli.Command, cfg domain.RedactConfig, values map[string]string) (services.RedactOptions, error) {
+	if err := cfg.Validate(); err != nil {
+		return services.RedactOptions{}, run.NewConfigError("invalid redact configuration", err)
+	}
+
+	encodings, err := cfg.EnabledEncodings()
+	if err != nil {
+		return services.RedactOptions{}, run.NewConfigError("invalid redact configuration", err)
+	}
+
+	modeName := string(cfg.Mode)
+	if cmd.IsSet("redact-mode") {
+		modeName = cmd.String("redact-mode")
+	}
+	mode, err := domain.ParseRedactMode(modeName)
+	if err != nil {
+		return services.RedactOptions{}, run.NewConfigError("invalid redact mode", err)
+	}
+
+	policies := make(map[string]domain.RedactPolicy)
+	for key := range values {
+		if policy := cfg.PolicyFor(key); policy != "" {
+			policies[key] = policy
+		}
+	}
+
+	return services.RedactOptions{
+		Mode:        mode,
+		Encodings:   encodings,
+		Placeholder: cfg.Placeholder,
+		Policies:    policies,
+	}, nil
+}
+
+// fetchSnapshot fetches the remote variables, and the secrets when enabled
+func (h *RunHandler) fetchSnapshot(ctx context.Context, secrets bool) (run.EnvSnapshot, error) {
+	var snapshot run.EnvSnapshot
+
+	env, err := h.injectEnvUseCase.Execute(ctx)
+	if err != nil {
+		return snapshot, err
+	}
+	snapshot.Env = env
+
+	if secrets {
+		snapshot.Secrets, err = h.injectSecretUseCase.Execute(ctx)
+		if err != nil {
+			return snapshot, err
+		}
+	}
+
+	return snapshot, nil
+}
+
+// runWatched runs the command and starts it again with the new values
+// whenever the remote variables change, until it exits on its own
+func (h *RunHandler) runWatched(
+	ctx context.Context,
+	cmd *cli.Command,
+	snapshot run.EnvSnapshot,
+	secrets bool,
+	execute func(context.Context, run.EnvSnapshot) (int, error),
+) (int, error) {
+	watchCtx, stopWatching := context.WithCancel(ctx)
+	defer stopWatching()
+
+	changes := h.watchUseCase.Execute(watchCtx, run.WatchOptions{
+		Interval: cmd.Duration("watch-interval"),
+		Current:  snapshot,
+		Secrets:  secrets,
+	})
+
+	for {
+		runCtx, stop := context.WithCancel(ctx)
+
+		// err is set before the exit status is sent
+		var err error
+		exited := make(chan int, 1)
+		go func() {
+			code, execErr := execute(runCtx, snapshot)
+			err = execErr
+			exited <- code
+		}()
+
+		select {
+		case code := <-exited:
+			stop()
+			return code, err
+		case next, ok := <-changes:
+			stop()
+			code := <-exited
+			if !ok || err != nil {
+				return code, err
+			}
+
+			// The terminal is back to normal once the command is gone
+			printEnvChanges(os.Stderr, run.DiffSnapshots(snapshot, next), next.Values())
+			snapshot = next
+		}
+	}
+}
+
+// printEnvChanges lists the keys that changed before a restart, values masked
+func printEnvChanges(w io.Writer, changes run.EnvChanges, values map[string]string) {
+	fmt.Fprintln(w, "envsync: remote variables changed, restarting the command")
+	for _, key := range changes.Added {
+		fmt.Fprintf(w, "  + %s = %s\n", key, utils.MaskValue(values[key]))
+	}
+	for _, key := range changes.Updated {
+		fmt.Fprintf(w, "  ~ %s = %s\n", key, utils.MaskValue(values[key]))
+	}
+	for _, key := range changes.Removed {
+		fmt.Fprintf(w, "  - %s\n", key)
+	}
+}
+
+// processes returns the processes of --procfile, or of the [processes] table
+// when no command is given. It returns nil to run a single command.
+func (h *RunHandler) processes(cmd *cli.Command, cfg *domain.SyncConfig) ([]domain.Process, error) {
+	command := cmd.Args().Len() > 0 || cmd.IsSet("command")
+
+	if path := cmd.String("procfile"); path != "" {
+		if command {
+			return nil, run.NewConfigError("pass either a command or --procfile, not both", nil)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, run.NewConfigError("failed to read Procfile", err)
+		}
+		processes, err := domain.ParseProcfile(data)
+		if err != nil {
+			return nil, run.NewConfigError("invalid Procfile "+path, err)
+		}
+		return processes, nil
+	}
+
+	if command || len(cfg.Processes) == 0 {
+		return nil, nil
+	}
+
+	processes, err := cfg.ProcessList()
+	if err != nil {
+		return nil, run.NewConfigError("invalid processes configuration", err)
+	}
+	return processes, nil
+}
+
+// commandArgs returns the argv of the command to run. Everything after -- is
+// taken verbatim, or handed to the shell with --shell: a single argument as
+// the script, several quoted one by one and joined. The older
+// --command string always goes through the shell so quoting keeps working.
+func (h *RunHandler) commandArgs(cmd *cli.Command) ([]string, error) {
+	args := cmd.Args().Slice()
+
+	if cmd.IsSet("command") {
+		if len(args) > 0 {
+			return nil, run.NewConfigError("pass the command either after -- or with --command, not both", nil)
+		}
+		return utils.ShellCommand(cmd.String("command")), nil
+	}
+
+	if len(args) == 0 {
+		return nil, run.NewConfigError("no command given, usage: envsync run [flags] -- <command> [args...]", nil)
+	}
+
+	if cmd.Bool("shell") {
+		// A single argument is the script itself, several are quoted so
+		// each reaches the command whole
+		if len(args) == 1 {
+			return utils.ShellCommand(args[0]), nil
+		}
+		return utils.ShellCommand(utils.ShellJoin(args)), nil
+	}
+
+	return args, nil
+}
+
+// runExitError turns an error into the exit code envsync run terminates with
+func runExitError(err error) error {
+	var runErr *run.RunError
+	if errors.As(err, &runErr) {
+		return cli.Exit("envsync: "+err.Error(), runErr.ExitCode())
+	}
+
+	var exitCoder cli.ExitCoder
+	if errors.As(err, &exitCoder) {
+		return err
+	}
+
+	return cli.Exit("envsync: "+err.Error(), run.ExitCodeSetup)
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"testing"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/EnvSync-Cloud/envsync-cli/internal/features/usecases/run"
+)
+
+func TestRunExitError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{name: "config error", err: run.NewConfigError("invalid --env", nil), expected: run.ExitCodeConfig},
+		{name: "wrapped fetch error", err: fmt.Errorf("run: %w", run.NewFetchError("failed to fetch secrets", nil)), expected: run.ExitCodeFetch},
+		{name: "decrypt error", err: run.NewDecryptError("failed to decrypt", nil), expected: run.ExitCodeDecrypt},
+		{name: "command not found", err: run.NewStartError("nope", exec.ErrNotFound), expected: run.ExitCodeNotFound},
+		{name: "exit status of the command", err: cli.Exit("", 3), expected: 3},
+		{name: "other error", err: errors.New("boom"), expected: run.ExitCodeSetup},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var exitCoder cli.ExitCoder
+			if !errors.As(runExitError(tt.err), &exitCoder) {
+				t.Fatalf("Expected an exit coder for %v", tt.err)
+			}
+			if exitCoder.ExitCode() != tt.expected {
+				t.Errorf("Expected exit code %d, got %d", tt.expected, exitCoder.ExitCode())
+			}
+		})
+	}
+}
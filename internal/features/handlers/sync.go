@@ -2,81 +2,280 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/urfave/cli/v3"
 
+	"github.com/EnvSync-Cloud/envsync-cli/internal/domain"
 	"github.com/EnvSync-Cloud/envsync-cli/internal/features/usecases/sync"
 	"github.com/EnvSync-Cloud/envsync-cli/internal/presentation/formatters"
+	"github.com/EnvSync-Cloud/envsync-cli/internal/utils"
 )
 
 type SyncHandler struct {
-	pullUseCase sync.PullUseCase
-	pushUseCase sync.PushUseCase
-	formatter   *formatters.SyncFormatter
+	pullUseCase        sync.PullUseCase
+	pushUseCase        sync.PushUseCase
+	listBackupsUseCase sync.ListBackupsUseCase
+	restoreUseCase     sync.RestoreUseCase
+	diffUseCase        sync.DiffUseCase
+	formatter          *formatters.SyncFormatter
 }
 
 func NewSyncHandler(
 	pullUseCase sync.PullUseCase,
 	pushUseCase sync.PushUseCase,
+	listBackupsUseCase sync.ListBackupsUseCase,
+	restoreUseCase sync.RestoreUseCase,
+	diffUseCase sync.DiffUseCase,
 	formatter *formatters.SyncFormatter,
 ) *SyncHandler {
 	return &SyncHandler{
-		pullUseCase: pullUseCase,
-		pushUseCase: pushUseCase,
-		formatter:   formatter,
+		pullUseCase:        pullUseCase,
+		pushUseCase:        pushUseCase,
+		listBackupsUseCase: listBackupsUseCase,
+		restoreUseCase:     restoreUseCase,
+		diffUseCase:        diffUseCase,
+		formatter:          formatter,
 	}
 }
 
 func (h *SyncHandler) Pull(ctx context.Context, cmd *cli.Command) error {
-	config := cmd.String("config")
+	opts := h.syncOptions(cmd)
 
-	diff, err := h.pullUseCase.Execute(ctx, config)
+	diffs, err := h.pullUseCase.Execute(ctx, opts)
+
+	// Files synced before a failure were written, report them either way
+	return h.finishSync(cmd, "Pull", diffs, err)
+}
+
+func (h *SyncHandler) Push(ctx context.Context, cmd *cli.Command) error {
+	opts := h.syncOptions(cmd)
+
+	diffs, err := h.pushUseCase.Execute(ctx, opts)
+
+	// Files synced before a failure were written, report them either way
+	return h.finishSync(cmd, "Push", diffs, err)
+}
+
+func (h *SyncHandler) Restore(ctx context.Context, cmd *cli.Command) error {
+	opts := sync.RestoreOptions{
+		ConfigPath:  cmd.String("config"),
+		EnvFile:     cmd.String("file"),
+		At:          cmd.String("at"),
+		DryRun:      cmd.Bool("dry-run"),
+		Confirmed:   cmd.Bool("yes"),
+		Interactive: !cmd.Bool("json") && utils.IsInteractive(),
+	}
+
+	if cmd.Bool("list") {
+		backups, err := h.listBackupsUseCase.Execute(ctx, opts)
+		if err != nil {
+			return err
+		}
+		if cmd.Bool("json") {
+			return h.formatter.FormatJSON(cmd.Writer, backups)
+		}
+		return h.formatter.FormatBackups(cmd.Writer, backups)
+	}
+
+	restore, err := h.restoreUseCase.Execute(ctx, opts)
 	if err != nil {
+		if errors.Is(err, tea.ErrProgramKilled) {
+			fmt.Fprintln(cmd.Writer, "Restore cancelled.")
+			return nil
+		}
 		return err
 	}
 
-	if len(diff.Warnings) > 0 {
-		// Handle warnings, e.g., print or log them
-		for _, warning := range diff.Warnings {
-			fmt.Printf("Warning: %s\n", warning)
-		}
-		return nil
+	changes := domain.ChangeSet{
+		Added:   restore.Added,
+		Updated: restore.Updated,
+		Deleted: restore.Deleted,
 	}
+	plan := formatters.NewPlan("restore", restore.DryRun, changes, nil, nil)
+	plan.EnvFile = restore.Backup.EnvFile
 
-	if len(diff.Added) > 0 || len(diff.Updated) > 0 || len(diff.Deleted) > 0 {
-		// Handle the sync response, e.g., print or log the changes
-		fmt.Printf("Sync completed with %d added, %d updated, and %d deleted variables.\n",
-			len(diff.Added), len(diff.Updated), len(diff.Deleted))
-	} else {
-		fmt.Printf("No changes detected during sync.\n")
+	if cmd.Bool("json") {
+		return h.formatter.FormatJSON(cmd.Writer, struct {
+			Backup domain.EnvBackup `json:"backup"`
+			formatters.Plan
+		}{restore.Backup, plan})
+	}
+
+	target := "backup " + restore.Backup.ID + " → " + restore.Backup.EnvFile
+	if restore.DryRun {
+		return h.formatter.FormatPlan(cmd.Writer, plan, "Restore", target)
 	}
 
+	fmt.Fprintf(cmd.Writer, "Restore (%s) completed with %d added, %d updated, and %d deleted variables.\n",
+		target, len(restore.Added), len(restore.Updated), len(restore.Deleted))
 	return nil
 }
 
-func (h *SyncHandler) Push(ctx context.Context, cmd *cli.Command) error {
-	config := cmd.String("config")
+func (h *SyncHandler) Diff(ctx context.Context, cmd *cli.Command) error {
+	opts := sync.DiffOptions{
+		ConfigPath: cmd.String("config"),
+		EnvTypes:   cmd.StringSlice("env"),
+		Apps:       cmd.StringSlice("app"),
+		Only:       cmd.StringSlice("only"),
+		Exclude:    cmd.StringSlice("exclude"),
+	}
 
-	diff, err := h.pushUseCase.Execute(ctx, config)
+	responses, err := h.diffUseCase.Execute(ctx, opts)
 	if err != nil {
 		return err
 	}
 
-	if len(diff.Warnings) > 0 {
-		// Handle warnings, e.g., print or log them
-		for _, warning := range diff.Warnings {
-			fmt.Printf("Warning: %s\n", warning)
+	diffs := make([]formatters.Diff, len(responses))
+	for i, response := range responses {
+		diffs[i] = h.buildDiff(response, cmd.Bool("show-values"))
+	}
+
+	if cmd.Bool("json") {
+		// Same shape as pull and push: one object for a single env file
+		if len(diffs) == 1 {
+			return h.formatter.FormatJSON(cmd.Writer, diffs[0])
+		}
+		return h.formatter.FormatJSON(cmd.Writer, diffs)
+	}
+
+	for i, diff := range diffs {
+		if i > 0 {
+			fmt.Fprintln(cmd.Writer)
+		}
+		if err := h.formatter.FormatDiff(cmd.Writer, diff); err != nil {
+			return err
 		}
+	}
+
+	return nil
+}
+
+func (h *SyncHandler) buildDiff(response sync.DiffResponse, showValues bool) formatters.Diff {
+	display := utils.MaskValue
+	if showValues {
+		display = func(value string) string { return value }
+	}
+
+	diff := formatters.Diff{
+		From:       response.From,
+		To:         response.To,
+		HasChanges: response.HasChanges(),
+		Added:      make([]formatters.PlanEntry, 0, len(response.Added)),
+		Changed:    make([]formatters.DiffChangeEntry, 0, len(response.Changed)),
+		Removed:    make([]formatters.PlanEntry, 0, len(response.Removed)),
+	}
+	for _, v := range response.Added {
+		diff.Added = append(diff.Added, formatters.PlanEntry{Key: v.Key, Value: display(v.Value)})
+	}
+	for _, c := range response.Changed {
+		diff.Changed = append(diff.Changed, formatters.DiffChangeEntry{Key: c.Key, From: display(c.From), To: display(c.To)})
+	}
+	for _, v := range response.Removed {
+		diff.Removed = append(diff.Removed, formatters.PlanEntry{Key: v.Key, Value: display(v.Value)})
+	}
+
+	return diff
+}
+
+func (h *SyncHandler) syncOptions(cmd *cli.Command) sync.SyncOptions {
+	return sync.SyncOptions{
+		ConfigPath:  cmd.String("config"),
+		DryRun:      cmd.Bool("dry-run"),
+		Strategy:    cmd.String("strategy"),
+		Interactive: !cmd.Bool("json") && utils.IsInteractive(),
+		Only:        cmd.StringSlice("only"),
+		Exclude:     cmd.StringSlice("exclude"),
+
+		ConfirmProtected: cmd.Bool("confirm-protected"),
+		ConfirmDeletes:   cmd.Bool("confirm-deletes"),
+	}
+}
+
+func (h *SyncHandler) finishSync(cmd *cli.Command, operation string, diffs []sync.SyncResponse, err error) error {
+	if printErr := h.printSyncResults(cmd, operation, diffs); printErr != nil && err == nil {
+		return printErr
+	}
+
+	if errors.Is(err, tea.ErrProgramKilled) {
+		fmt.Fprintf(cmd.Writer, "%s cancelled.\n", operation)
 		return nil
 	}
 
-	if len(diff.Added) > 0 || len(diff.Updated) > 0 || len(diff.Deleted) > 0 {
-		// Handle the sync response, e.g., print or log the changes
-		fmt.Printf("Sync completed with %d added, %d updated, and %d deleted variables.\n",
-			len(diff.Added), len(diff.Updated), len(diff.Deleted))
+	return err
+}
+
+func (h *SyncHandler) printSyncResults(cmd *cli.Command, operation string, diffs []sync.SyncResponse) error {
+	if len(diffs) == 0 {
+		return nil
+	}
+
+	if cmd.Bool("json") {
+		plans := make([]formatters.Plan, len(diffs))
+		for i, diff := range diffs {
+			plans[i] = h.buildPlan(operation, diff)
+		}
+		// Keep the single object output for projects syncing one file
+		if len(plans) == 1 {
+			return h.formatter.FormatJSON(cmd.Writer, plans[0])
+		}
+		return h.formatter.FormatJSON(cmd.Writer, plans)
+	}
+
+	for i, diff := range diffs {
+		if i > 0 {
+			fmt.Fprintln(cmd.Writer)
+		}
+		if err := h.printSyncResult(cmd, operation, diff); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (h *SyncHandler) buildPlan(operation string, diff sync.SyncResponse) formatters.Plan {
+	changes := domain.ChangeSet{
+		Added:   diff.Added,
+		Updated: diff.Updated,
+		Deleted: diff.Deleted,
+	}
+	plan := formatters.NewPlan(strings.ToLower(operation), diff.DryRun, changes, diff.Conflicts, diff.Warnings)
+	plan.EnvFile = diff.EnvFile
+	plan.EnvType = diff.EnvType
+	return plan
+}
+
+func (h *SyncHandler) printSyncResult(cmd *cli.Command, operation string, diff sync.SyncResponse) error {
+	target := diff.EnvType + " → " + diff.EnvFile
+	if operation == "Push" {
+		target = diff.EnvFile + " → " + diff.EnvType
+	}
+
+	if diff.DryRun {
+		return h.formatter.FormatPlan(cmd.Writer, h.buildPlan(operation, diff), operation, target)
+	}
+
+	for _, warning := range diff.Warnings {
+		fmt.Fprintf(cmd.Writer, "Warning: %s\n", warning)
+	}
+
+	if diff.HasChanges() {
+		fmt.Fprintf(cmd.Writer, "%s (%s) completed with %d added, %d updated, and %d deleted variables.\n",
+			operation, target, len(diff.Added), len(diff.Updated), len(diff.Deleted))
 	} else {
-		fmt.Printf("No changes detected during sync.\n")
+		fmt.Fprintf(cmd.Writer, "%s (%s): no changes detected.\n", operation, target)
+	}
+
+	if len(diff.Resolved) > 0 {
+		fmt.Fprintf(cmd.Writer, "%d conflicting variable(s) were resolved.\n", len(diff.Resolved))
+	}
+
+	if len(diff.Conflicts) > 0 {
+		fmt.Fprintf(cmd.Writer, "%d conflicting variable(s) were left untouched.\n", len(diff.Conflicts))
 	}
 
 	return nil
@@ -106,6 +305,8 @@ func (h *SyncHandler) formatUseCaseError(cmd *cli.Command, err error) error {
 			return h.formatter.FormatError(cmd.Writer, "Corrupted file error: "+e.Message)
 		case sync.SyncErrorCodeServiceError:
 			return h.formatter.FormatError(cmd.Writer, "Service error: "+e.Message)
+		case sync.SyncErrorCodeConflict:
+			return h.formatter.FormatError(cmd.Writer, "Conflict error: "+e.Message)
 		default:
 			return h.formatter.FormatError(cmd.Writer, "Service error: "+e.Message)
 		}
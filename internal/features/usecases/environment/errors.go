@@ -25,6 +25,7 @@ var (
 	ErrEnvAlreadyExists  = errors.New("environment already exists")
 	ErrEnvLocked         = errors.New("environment is locked and cannot be modified")
 	ErrEnvBackupFailed   = errors.New("failed to create environment backup")
+	ErrEnvNotConfirmed   = errors.New("confirmation required")
 
 	// External service errors
 	ErrEnvServiceUnavailable = errors.New("environment service is currently unavailable")
@@ -18,3 +18,43 @@ type SwitchEnvUseCase interface {
 type DeleteEnvUseCase interface {
 	Execute(context.Context, string) error
 }
+
+type PromoteEnvUseCase interface {
+	Execute(context.Context, PromoteOptions) (PromoteResponse, error)
+}
+
+// PromoteOptions selects the variables copied from one environment type to another
+type PromoteOptions struct {
+	ConfigPath string
+	// From and To are environment types of the configured app, by name or ID
+	From string
+	To   string
+	// Keys limits the promotion to keys matching these globs, all keys when empty
+	Keys []string
+	// SkipExisting leaves keys that already exist in the target untouched
+	SkipExisting bool
+	DryRun       bool
+	// ConfirmProtected allows promoting to a protected environment type without prompting
+	ConfirmProtected bool
+	// Confirmed applies the plan to other environment types without prompting
+	Confirmed bool
+	// Interactive allows prompting the user in the terminal
+	Interactive bool
+}
+
+// PromoteResponse is the plan of a promotion, or its result once applied
+type PromoteResponse struct {
+	From    string                       `json:"from"`
+	To      string                       `json:"to"`
+	Added   []domain.EnvironmentVariable `json:"added"`
+	Updated []domain.EnvironmentVariable `json:"updated"`
+	// Skipped are existing keys left untouched because of SkipExisting
+	Skipped []string `json:"skipped"`
+	// Missing are requested keys the source environment type does not have
+	Missing []string `json:"missing"`
+	DryRun  bool     `json:"dry_run"`
+}
+
+func (r PromoteResponse) HasChanges() bool {
+	return len(r.Added) > 0 || len(r.Updated) > 0
+}
@@ -0,0 +1,174 @@
+package environment
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/EnvSync-Cloud/envsync-cli/internal/domain"
+	"github.com/EnvSync-Cloud/envsync-cli/internal/presentation/tui/factory"
+	"github.com/EnvSync-Cloud/envsync-cli/internal/services"
+)
+
+type promoteEnvUseCase struct {
+	envTypeService services.EnvTypeService
+	tui            *factory.EnvFactory
+}
+
+func NewPromoteEnvUseCase() PromoteEnvUseCase {
+	envTypeService := services.NewEnvTypeService()
+	tui := factory.NewEnvFactory()
+
+	return &promoteEnvUseCase{
+		envTypeService: envTypeService,
+		tui:            tui,
+	}
+}
+
+func (uc *promoteEnvUseCase) Execute(ctx context.Context, opts PromoteOptions) (PromoteResponse, error) {
+	filter := domain.KeyFilter{Include: opts.Keys}
+	if err := filter.Validate(); err != nil {
+		return PromoteResponse{}, NewValidationError("invalid --keys pattern", "", err)
+	}
+
+	cfg, err := services.ReadSyncConfig(opts.ConfigPath)
+	if err != nil {
+		return PromoteResponse{}, NewFileSystemError("failed to read sync config", err)
+	}
+
+	from, to, err := uc.resolveEnvTypes(cfg.AppID, opts.From, opts.To)
+	if err != nil {
+		return PromoteResponse{}, err
+	}
+
+	fromService := services.NewSyncServiceForTarget(opts.ConfigPath, cfg, domain.SyncTarget{EnvType: from})
+	toService := services.NewSyncServiceForTarget(opts.ConfigPath, cfg, domain.SyncTarget{EnvType: to})
+
+	source, err := readRemoteEnv(fromService, from)
+	if err != nil {
+		return PromoteResponse{}, err
+	}
+
+	target, err := readRemoteEnv(toService, to)
+	if err != nil {
+		return PromoteResponse{}, err
+	}
+
+	response := uc.buildPlan(from, to, source, target, filter, opts)
+	if opts.DryRun || !response.HasChanges() {
+		return response, nil
+	}
+
+	// The plan is shown before anything is written. A protected target needs
+	// its name typed, any other a yes unless --yes is given or nobody can answer.
+	changes := domain.ChangeSet{Added: response.Added, Updated: response.Updated}
+	if to.IsProtected && !opts.ConfirmProtected {
+		if !opts.Interactive {
+			return PromoteResponse{}, NewPermissionError(
+				fmt.Sprintf("environment type %s is protected, pass --confirm-protected to promote to it", to.Name),
+				ErrEnvNotConfirmed,
+			)
+		}
+		if err := uc.tui.ConfirmPromoteTUI(from.Name, to, changes, response.Skipped, response.Missing); err != nil {
+			return PromoteResponse{}, err
+		}
+	} else if !to.IsProtected && !opts.Confirmed && opts.Interactive {
+		if err := uc.tui.ConfirmPromoteTUI(from.Name, to, changes, response.Skipped, response.Missing); err != nil {
+			return PromoteResponse{}, err
+		}
+	}
+
+	// Promotion never deletes, keys missing from the source stay in the target
+	envSync := &domain.EnvironmentSync{
+		ToAdd:    response.Added,
+		ToUpdate: response.Updated,
+	}
+	if err := toService.WriteRemoteEnv(envSync); err != nil {
+		return PromoteResponse{}, NewServiceError("failed to write environment variables to "+to.Name, err)
+	}
+
+	return response, nil
+}
+
+func (uc *promoteEnvUseCase) resolveEnvTypes(appID, fromRef, toRef string) (domain.EnvType, domain.EnvType, error) {
+	if fromRef == "" || toRef == "" {
+		return domain.EnvType{}, domain.EnvType{}, NewValidationError("both --from and --to are required", "", ErrEmptyEnvName)
+	}
+
+	envTypes, err := uc.envTypeService.GetEnvTypesByAppID(appID)
+	if err != nil {
+		return domain.EnvType{}, domain.EnvType{}, NewServiceError("failed to fetch environment types", err)
+	}
+
+	from, ok := domain.FindEnvType(envTypes, fromRef)
+	if !ok {
+		return domain.EnvType{}, domain.EnvType{}, NewNotFoundError("environment type "+fromRef+" not found", ErrInvalidEnvName)
+	}
+
+	to, ok := domain.FindEnvType(envTypes, toRef)
+	if !ok {
+		return domain.EnvType{}, domain.EnvType{}, NewNotFoundError("environment type "+toRef+" not found", ErrInvalidEnvName)
+	}
+
+	if from.ID == to.ID {
+		return domain.EnvType{}, domain.EnvType{}, NewValidationError("cannot promote "+from.Name+" to itself", "", ErrInvalidEnvName)
+	}
+
+	return from, to, nil
+}
+
+// buildPlan works out which source variables are created or updated in the target
+func (uc *promoteEnvUseCase) buildPlan(from, to domain.EnvType, source, target map[string]string, filter domain.KeyFilter, opts PromoteOptions) PromoteResponse {
+	targetVars := make(map[string]domain.EnvironmentVariable, len(target))
+	for k, v := range target {
+		targetVars[k] = domain.EnvironmentVariable{Key: k, Value: v}
+	}
+
+	envSync := domain.NewEnvironmentSync(source, targetVars)
+	envSync.Filter = filter
+	envSync.CalculateDiff()
+
+	response := PromoteResponse{
+		From:    from.Name,
+		To:      to.Name,
+		Added:   envSync.ToAdd,
+		Updated: envSync.ToUpdate,
+		DryRun:  opts.DryRun,
+	}
+
+	if opts.SkipExisting {
+		for _, v := range response.Updated {
+			response.Skipped = append(response.Skipped, v.Key)
+		}
+		response.Updated = nil
+	}
+
+	// Keys asked for by name that the source does not have are most likely typos
+	for _, key := range opts.Keys {
+		if _, ok := source[key]; !ok && !strings.ContainsAny(key, "*?[") {
+			response.Missing = append(response.Missing, key)
+		}
+	}
+
+	byKey := func(a, b domain.EnvironmentVariable) int { return cmp.Compare(a.Key, b.Key) }
+	slices.SortFunc(response.Added, byKey)
+	slices.SortFunc(response.Updated, byKey)
+	slices.Sort(response.Skipped)
+
+	return response
+}
+
+func readRemoteEnv(syncService services.SyncService, envType domain.EnvType) (map[string]string, error) {
+	vars, err := syncService.ReadRemoteEnv()
+	if err != nil {
+		return nil, NewServiceError("failed to read environment variables of "+envType.Name, err)
+	}
+
+	env := make(map[string]string, len(vars))
+	for _, v := range vars {
+		env[v.Key] = v.Value
+	}
+	return env, nil
+}
@@ -0,0 +1,64 @@
+package environment
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/EnvSync-Cloud/envsync-cli/internal/domain"
+)
+
+func TestBuildPlan(t *testing.T) {
+	from := domain.EnvType{Name: "staging"}
+	to := domain.EnvType{Name: "prod"}
+	source := map[string]string{"API_URL": "https://staging", "DB_HOST": "db", "DB_USER": "app", "NEW": "1"}
+	target := map[string]string{"API_URL": "https://prod", "DB_HOST": "db", "ONLY_PROD": "x"}
+
+	tests := []struct {
+		name     string
+		opts     PromoteOptions
+		expected PromoteResponse
+	}{
+		{
+			name: "overwrite",
+			opts: PromoteOptions{},
+			expected: PromoteResponse{
+				From:    "staging",
+				To:      "prod",
+				Added:   []domain.EnvironmentVariable{{Key: "DB_USER", Value: "app"}, {Key: "NEW", Value: "1"}},
+				Updated: []domain.EnvironmentVariable{{Key: "API_URL", Value: "https://staging"}},
+			},
+		},
+		{
+			name: "skip existing",
+			opts: PromoteOptions{SkipExisting: true, DryRun: true},
+			expected: PromoteResponse{
+				From:    "staging",
+				To:      "prod",
+				Added:   []domain.EnvironmentVariable{{Key: "DB_USER", Value: "app"}, {Key: "NEW", Value: "1"}},
+				Skipped: []string{"API_URL"},
+				DryRun:  true,
+			},
+		},
+		{
+			name: "missing source keys",
+			opts: PromoteOptions{Keys: []string{"DB_*", "API_URL", "TYPO", "MISSING_*"}},
+			expected: PromoteResponse{
+				From:    "staging",
+				To:      "prod",
+				Added:   []domain.EnvironmentVariable{{Key: "DB_USER", Value: "app"}},
+				Updated: []domain.EnvironmentVariable{{Key: "API_URL", Value: "https://staging"}},
+				Missing: []string{"TYPO"},
+			},
+		},
+	}
+
+	uc := &promoteEnvUseCase{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := uc.buildPlan(from, to, source, target, domain.KeyFilter{Include: tt.opts.Keys}, tt.opts)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package run
+
+import (
+	"fmt"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// DefaultCleanEnvAllowlist are the variables kept from the parent process
+// with --clean-env, enough for most commands to find binaries and files
+var DefaultCleanEnvAllowlist = []string{
+	"PATH", "HOME", "USER", "LOGNAME", "SHELL", "TMPDIR", "LANG", "TERM",
+}
+
+// windowsEnvAllowlist is needed for most programs to start at all on Windows
+var windowsEnvAllowlist = []string{
+	"SystemRoot", "SystemDrive", "ComSpec", "PATHEXT", "TEMP", "TMP", "USERPROFILE", "APPDATA", "LOCALAPPDATA",
+}
+
+// colorEnv keeps colors in the output of commands attached to a pty, even
+// though it is envsync that reads from the terminal
+var colorEnv = map[string]string{
+	"FORCE_COLOR":    "1",
+	"CLICOLOR_FORCE": "1",
+}
+
+// EnvOptions controls how the environment of the command is built
+type EnvOptions struct {
+	// Clean starts from an empty environment instead of the inherited one
+	Clean bool
+	// Allow are the inherited variables kept when Clean is set
+	Allow []string
+	// ForceColor sets FORCE_COLOR, CLICOLOR_FORCE and TERM for a pty
+	ForceColor bool
+	// KeepTerm leaves the inherited TERM alone when ForceColor is set
+	KeepTerm bool
+	// Overrides are the --env KEY=VAL values
+	Overrides map[string]string
+}
+
+// BuildEnv returns the environment of the command. Later sources win:
+//
+//  1. the inherited environment, only the allowlist with --clean-env
+//  2. color and TERM defaults when attached to a pty
+//  3. remote environment variables
+//  4. secrets
+//  5. --env KEY=VAL overrides
+//
+// The environment of envsync itself is never modified.
+func BuildEnv(inherited []string, remote, secrets map[string]string, opts EnvOptions) []string {
+	env := newOrderedEnv()
+
+	allow := slices.Clone(opts.Allow)
+	if runtime.GOOS == "windows" {
+		allow = append(allow, windowsEnvAllowlist...)
+	}
+	for _, kv := range inherited {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok {
+			continue
+		}
+		if opts.Clean && !containsEnvKey(allow, key) {
+			continue
+		}
+		env.set(key, value)
+	}
+
+	if opts.ForceColor {
+		env.setAll(colorEnv)
+		if !opts.KeepTerm {
+			env.set("TERM", "xterm-256color")
+		}
+	}
+
+	env.setAll(remote)
+	env.setAll(secrets)
+	env.setAll(opts.Overrides)
+
+	return env.environ()
+}
+
+// ParseEnvOverrides parses --env KEY=VAL flags
+func ParseEnvOverrides(values []string) (map[string]string, error) {
+	overrides := make(map[string]string, len(values))
+	for _, kv := range values {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok || key == "" {
+			return nil, NewConfigError(fmt.Sprintf("invalid --env %q, expected KEY=VALUE", kv), nil)
+		}
+		overrides[key] = value
+	}
+	return overrides, nil
+}
+
+// containsEnvKey matches variable names the way the platform does,
+// case-insensitively on Windows
+func containsEnvKey(keys []string, key string) bool {
+	return slices.ContainsFunc(keys, func(k string) bool {
+		if runtime.GOOS == "windows" {
+			return strings.EqualFold(k, key)
+		}
+		return k == key
+	})
+}
+
+// orderedEnv keeps the order variables were first set in so the child sees
+// the inherited environment in its original order
+type orderedEnv struct {
+	keys   []string
+	values map[string]string
+}
+
+func newOrderedEnv() *orderedEnv {
+	return &orderedEnv{values: make(map[string]string)}
+}
+
+func (e *orderedEnv) set(key, value string) {
+	if _, ok := e.values[key]; !ok {
+		e.keys = append(e.keys, key)
+	}
+	e.values[key] = value
+}
+
+// setAll sets the variables of env in alphabetical order
+func (e *orderedEnv) setAll(env map[string]string) {
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		e.set(key, env[key])
+	}
+}
+
+func (e *orderedEnv) environ() []string {
+	environ := make([]string, len(e.keys))
+	for i, key := range e.keys {
+		environ[i] = key + "=" + e.values[key]
+	}
+	return environ
+}
@@ -0,0 +1,150 @@
+package run
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// envMap returns the variables of environ by name, failing on duplicates
+func envMap(t *testing.T, environ []string) map[string]string {
+	t.Helper()
+
+	env := make(map[string]string, len(environ))
+	for _, kv := range environ {
+		key, value, _ := strings.Cut(kv, "=")
+		if _, ok := env[key]; ok {
+			t.Fatalf("Duplicate variable %s in %v", key, environ)
+		}
+		env[key] = value
+	}
+	return env
+}
+
+func TestBuildEnvPrecedence(t *testing.T) {
+	inherited := []string{"A=inherited", "B=inherited", "C=inherited", "D=inherited", "E=inherited"}
+	remote := map[string]string{"B": "remote", "C": "remote", "D": "remote", "E": "remote", "F": "remote"}
+	// File secrets reach BuildEnv as secrets holding the path of their file
+	secrets := map[string]string{"C": "secret", "D": "/tmp/envsync-secrets-1/D", "E": "secret"}
+	overrides := map[string]string{"E": "override", "G": "override"}
+
+	environ := BuildEnv(inherited, remote, secrets, EnvOptions{Overrides: overrides})
+
+	expected := map[string]string{
+		"A": "inherited",
+		"B": "remote",
+		"C": "secret",
+		"D": "/tmp/envsync-secrets-1/D",
+		"E": "override",
+		"F": "remote",
+		"G": "override",
+	}
+	got := envMap(t, environ)
+	for key, value := range expected {
+		if got[key] != value {
+			t.Errorf("Expected %s=%s, got %q", key, value, got[key])
+		}
+	}
+	if len(got) != len(expected) {
+		t.Errorf("Expected %d variables, got %v", len(expected), environ)
+	}
+
+	// Inherited variables keep their order, new ones follow
+	if !slices.Equal(environ[:5], []string{"A=inherited", "B=remote", "C=secret", "D=/tmp/envsync-secrets-1/D", "E=override"}) {
+		t.Errorf("Expected the inherited order to be kept, got %v", environ)
+	}
+}
+
+func TestBuildEnvCleanKeepsAllowlist(t *testing.T) {
+	inherited := []string{
+		"PATH=/usr/bin", "HOME=/home/dev", "USER=dev", "LANG=C.UTF-8",
+		"AWS_SECRET_ACCESS_KEY=leak", "EDITOR=vim", "GOPATH=/go",
+	}
+	remote := map[string]string{"API_URL": "https://api"}
+
+	environ := BuildEnv(inherited, remote, nil, EnvOptions{
+		Clean: true,
+		Allow: slices.Concat(DefaultCleanEnvAllowlist, []string{"GOPATH"}),
+	})
+
+	got := envMap(t, environ)
+	for _, key := range []string{"PATH", "HOME", "USER", "LANG", "GOPATH", "API_URL"} {
+		if _, ok := got[key]; !ok {
+			t.Errorf("Expected %s to be kept, got %v", key, environ)
+		}
+	}
+	for _, key := range []string{"AWS_SECRET_ACCESS_KEY", "EDITOR"} {
+		if _, ok := got[key]; ok {
+			t.Errorf("Expected %s to be dropped, got %v", key, environ)
+		}
+	}
+
+	// Without --clean-env everything is inherited
+	got = envMap(t, BuildEnv(inherited, remote, nil, EnvOptions{Allow: DefaultCleanEnvAllowlist}))
+	if got["AWS_SECRET_ACCESS_KEY"] != "leak" || got["EDITOR"] != "vim" {
+		t.Errorf("Expected the whole environment to be inherited, got %v", got)
+	}
+}
+
+func TestBuildEnvForceColor(t *testing.T) {
+	inherited := []string{"TERM=dumb"}
+
+	tests := []struct {
+		name     string
+		remote   map[string]string
+		opts     EnvOptions
+		expected map[string]string
+	}{
+		{
+			name:     "not in a pty",
+			opts:     EnvOptions{},
+			expected: map[string]string{"TERM": "dumb", "FORCE_COLOR": "", "CLICOLOR_FORCE": ""},
+		},
+		{
+			name:     "in a pty",
+			opts:     EnvOptions{ForceColor: true},
+			expected: map[string]string{"TERM": "xterm-256color", "FORCE_COLOR": "1", "CLICOLOR_FORCE": "1"},
+		},
+		{
+			name:     "in a pty with --keep-term",
+			opts:     EnvOptions{ForceColor: true, KeepTerm: true},
+			expected: map[string]string{"TERM": "dumb", "FORCE_COLOR": "1", "CLICOLOR_FORCE": "1"},
+		},
+		{
+			name:     "remote variables win over the defaults",
+			remote:   map[string]string{"TERM": "vt100", "FORCE_COLOR": "0"},
+			opts:     EnvOptions{ForceColor: true},
+			expected: map[string]string{"TERM": "vt100", "FORCE_COLOR": "0", "CLICOLOR_FORCE": "1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := envMap(t, BuildEnv(inherited, tt.remote, nil, tt.opts))
+			for key, value := range tt.expected {
+				if got[key] != value {
+					t.Errorf("Expected %s=%q, got %q", key, value, got[key])
+				}
+			}
+		})
+	}
+}
+
+func TestParseEnvOverrides(t *testing.T) {
+	overrides, err := ParseEnvOverrides([]string{"PORT=4000", "EMPTY=", "URL=a=b"})
+	if err != nil {
+		t.Fatalf("ParseEnvOverrides returned error: %v", err)
+	}
+	expected := map[string]string{"PORT": "4000", "EMPTY": "", "URL": "a=b"}
+	for key, value := range expected {
+		if v, ok := overrides[key]; !ok || v != value {
+			t.Errorf("Expected %s=%q, got %q", key, value, v)
+		}
+	}
+
+	for _, invalid := range []string{"PORT", "=4000"} {
+		if _, err := ParseEnvOverrides([]string{invalid}); err == nil {
+			t.Errorf("Expected an error for %q", invalid)
+		}
+	}
+}
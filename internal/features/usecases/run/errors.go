@@ -0,0 +1,109 @@
+package run
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// Exit codes of envsync run. The exit status of the child is passed through
+// as is, or 128+N when the child was killed by signal N. Failures inside
+// envsync use 121-124, right below the 126 and 127 a shell uses for commands
+// that cannot be executed or found, so CI can tell them apart.
+const (
+	// ExitCodeConfig is used when the project configuration or the flags are invalid
+	ExitCodeConfig = 121
+	// ExitCodeFetch is used when the app, variables or secrets cannot be fetched
+	ExitCodeFetch = 122
+	// ExitCodeDecrypt is used when secrets cannot be decrypted
+	ExitCodeDecrypt = 123
+	// ExitCodeSetup is used when the pty or the child process cannot be set up
+	ExitCodeSetup = 124
+	// ExitCodeNotExecutable is used when the command exists but cannot be executed
+	ExitCodeNotExecutable = 126
+	// ExitCodeNotFound is used when the command cannot be found
+	ExitCodeNotFound = 127
+)
+
+// RunError is a failure of envsync itself while preparing or running the command
+type RunError struct {
+	Code    int
+	Message string
+	Cause   error
+}
+
+func (e RunError) Error() string {
+	if e.Cause != nil {
+		return e.Message + ": " + e.Cause.Error()
+	}
+	return e.Message
+}
+
+func (e RunError) Unwrap() error {
+	return e.Cause
+}
+
+// ExitCode returns the exit code envsync run terminates with
+func (e RunError) ExitCode() int {
+	return e.Code
+}
+
+// Helper functions to create structured errors
+func NewConfigError(message string, cause error) *RunError {
+	return &RunError{
+		Code:    ExitCodeConfig,
+		Message: message,
+		Cause:   cause,
+	}
+}
+
+func NewFetchError(message string, cause error) *RunError {
+	return &RunError{
+		Code:    ExitCodeFetch,
+		Message: message,
+		Cause:   cause,
+	}
+}
+
+func NewDecryptError(message string, cause error) *RunError {
+	return &RunError{
+		Code:    ExitCodeDecrypt,
+		Message: message,
+		Cause:   cause,
+	}
+}
+
+func NewSetupError(message string, cause error) *RunError {
+	return &RunError{
+		Code:    ExitCodeSetup,
+		Message: message,
+		Cause:   cause,
+	}
+}
+
+// NewStartError classifies a failure to start the command the way a shell does
+func NewStartError(command string, cause error) *RunError {
+	switch {
+	case errors.Is(cause, exec.ErrNotFound), errors.Is(cause, fs.ErrNotExist):
+		return &RunError{Code: ExitCodeNotFound, Message: "command not found", Cause: cause}
+	case errors.Is(cause, fs.ErrPermission), errors.Is(cause, syscall.ENOEXEC):
+		return &RunError{Code: ExitCodeNotExecutable, Message: "cannot execute command", Cause: cause}
+	default:
+		return NewSetupError("failed to start "+command, cause)
+	}
+}
+
+// ExitStatus returns the exit status of a finished process the way a shell
+// reports it, 128+N for a process killed by signal N. Without a state, when
+// the process could not be waited for, it is ExitCodeSetup.
+func ExitStatus(state *os.ProcessState) int {
+	if state == nil {
+		return ExitCodeSetup
+	}
+	if status, ok := state.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return 128 + int(status.Signal())
+	}
+	return state.ExitCode()
+}
@@ -0,0 +1,62 @@
+package run
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os/exec"
+	"syscall"
+	"testing"
+)
+
+func TestNewStartError(t *testing.T) {
+	tests := []struct {
+		name     string
+		cause    error
+		expected int
+	}{
+		{name: "not in PATH", cause: &exec.Error{Name: "nope", Err: exec.ErrNotFound}, expected: ExitCodeNotFound},
+		{name: "ENOENT", cause: &fs.PathError{Op: "fork/exec", Path: "./nope", Err: syscall.ENOENT}, expected: ExitCodeNotFound},
+		{name: "EACCES", cause: &fs.PathError{Op: "fork/exec", Path: "./script", Err: syscall.EACCES}, expected: ExitCodeNotExecutable},
+		{name: "ENOEXEC", cause: &fs.PathError{Op: "fork/exec", Path: "./binary", Err: syscall.ENOEXEC}, expected: ExitCodeNotExecutable},
+		{name: "other failure", cause: errors.New("too many open files"), expected: ExitCodeSetup},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewStartError("cmd", tt.cause)
+			if err.ExitCode() != tt.expected {
+				t.Errorf("Expected exit code %d, got %d (%v)", tt.expected, err.ExitCode(), err)
+			}
+			if !errors.Is(err, tt.cause) {
+				t.Errorf("Expected %v to wrap %v", err, tt.cause)
+			}
+		})
+	}
+}
+
+func TestRunErrorExitCodes(t *testing.T) {
+	tests := []struct {
+		err      *RunError
+		expected int
+	}{
+		{err: NewConfigError("invalid flag", nil), expected: 121},
+		{err: NewFetchError("failed to fetch", nil), expected: 122},
+		{err: NewDecryptError("failed to decrypt", nil), expected: 123},
+		{err: NewSetupError("failed to set up", nil), expected: 124},
+	}
+
+	for _, tt := range tests {
+		wrapped := fmt.Errorf("run: %w", tt.err)
+		var runErr *RunError
+		if !errors.As(wrapped, &runErr) || runErr.ExitCode() != tt.expected {
+			t.Errorf("Expected exit code %d for %v, got %v", tt.expected, tt.err, runErr)
+		}
+	}
+}
+
+func TestExitStatusWithoutState(t *testing.T) {
+	if got := ExitStatus(nil); got != ExitCodeSetup {
+		t.Errorf("Expected %d, got %d", ExitCodeSetup, got)
+	}
+}
@@ -0,0 +1,60 @@
+//go:build !windows
+
+package run
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestExitStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		script   string
+		expected int
+	}{
+		{name: "success", script: "exit 0", expected: 0},
+		{name: "failure", script: "exit 3", expected: 3},
+		{name: "killed by SIGTERM", script: "kill -TERM $$", expected: 128 + 15},
+		{name: "killed by SIGKILL", script: "kill -KILL $$", expected: 128 + 9},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.Command("/bin/sh", "-c", tt.script)
+			cmd.Run()
+			if got := ExitStatus(cmd.ProcessState); got != tt.expected {
+				t.Errorf("Expected %d, got %d", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestNewStartErrorFromStart(t *testing.T) {
+	dir := t.TempDir()
+	notExecutable := filepath.Join(dir, "script")
+	if err := os.WriteFile(notExecutable, []byte("#!/bin/sh\n"), 0600); err != nil {
+		t.Fatalf("Failed to write script: %v", err)
+	}
+
+	tests := []struct {
+		command  string
+		expected int
+	}{
+		{command: "envsync-command-that-does-not-exist", expected: ExitCodeNotFound},
+		{command: filepath.Join(dir, "missing"), expected: ExitCodeNotFound},
+		{command: notExecutable, expected: ExitCodeNotExecutable},
+	}
+
+	for _, tt := range tests {
+		err := exec.Command(tt.command).Start()
+		if err == nil {
+			t.Fatalf("Expected %s not to start", tt.command)
+		}
+		if got := NewStartError(tt.command, err).ExitCode(); got != tt.expected {
+			t.Errorf("Expected exit code %d for %s, got %d (%v)", tt.expected, tt.command, got, err)
+		}
+	}
+}
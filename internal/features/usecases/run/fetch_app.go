@@ -21,7 +21,7 @@ func NewFetchAppUseCase() FetchAppUseCase {
 func (f *fetchAppUseCase) Execute(ctx context.Context, appID string) (*domain.Application, error) {
 	app, err := f.appService.GetAppByID(appID)
 	if err != nil {
-		return nil, err
+		return nil, NewFetchError("failed to fetch app", err)
 	}
 
 	return &app, nil
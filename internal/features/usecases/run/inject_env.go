@@ -2,39 +2,52 @@ package run
 
 import (
 	"context"
-	"os"
 
+	"github.com/EnvSync-Cloud/envsync-cli/internal/domain"
+	"github.com/EnvSync-Cloud/envsync-cli/internal/repository"
 	"github.com/EnvSync-Cloud/envsync-cli/internal/services"
 )
 
 type injectEnv struct {
+	// syncService overrides the service built from the context, for tests
 	syncService services.SyncService
 }
 
 func NewInjectEnv() InjectEnvUseCase {
-	s := services.NewSyncService()
+	return &injectEnv{}
+}
+
+// NewInjectEnvFromRepository returns a use case reading the variables from repo
+func NewInjectEnvFromRepository(repo repository.EnvVariableRepository) InjectEnvUseCase {
 	return &injectEnv{
-		syncService: s,
+		syncService: services.NewSyncServiceFromRepository(repo, domain.SyncConfig{}),
 	}
 }
 
 func (uc *injectEnv) Execute(ctx context.Context) (map[string]string, error) {
-	env, err := uc.readRemoteEnv()
-	if err != nil {
-		//TODO: handle error appropriately
+	syncService := uc.syncService
+	if syncService == nil {
+		configPath := ctx.Value("configPath").(string)
+		appID := ctx.Value("appID").(string)
+		envTypeID := ctx.Value("envTypeID").(string)
+
+		syncService = services.NewSyncServiceForTarget(
+			configPath,
+			domain.SyncConfig{AppID: appID, EnvTypeID: envTypeID},
+			domain.SyncTarget{EnvType: domain.EnvType{ID: envTypeID}},
+		)
 	}
 
-	for key, value := range env {
-		if err := os.Setenv(key, value); err != nil {
-			// TODO: handle error appropriately
-		}
+	env, err := uc.readRemoteEnv(syncService)
+	if err != nil {
+		return nil, NewFetchError("failed to fetch environment variables", err)
 	}
 
 	return env, nil
 }
 
-func (uc *injectEnv) readRemoteEnv() (map[string]string, error) {
-	remoteEnv, err := uc.syncService.ReadRemoteEnv()
+func (uc *injectEnv) readRemoteEnv(syncService services.SyncService) (map[string]string, error) {
+	remoteEnv, err := syncService.ReadRemoteEnv()
 	if err != nil {
 		return nil, err
 	}
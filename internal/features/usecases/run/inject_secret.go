@@ -2,7 +2,6 @@ package run
 
 import (
 	"context"
-	"os"
 
 	"github.com/EnvSync-Cloud/envsync-cli/internal/domain"
 	"github.com/EnvSync-Cloud/envsync-cli/internal/services"
@@ -28,35 +27,30 @@ func (i *injectSecretUseCase) Execute(ctx context.Context) (map[string]string, e
 
 	secrets, err := i.getAllSecrets(appID, envTypeID)
 	if err != nil {
-		return nil, err
+		return nil, NewFetchError("failed to fetch secrets", err)
 	}
 
 	var decryptedSecrets []domain.Secret
 	if !managedSecret {
 		privatePEM, err := i.extractPrivateKey(privateKeyPath)
 		if err != nil {
-			return nil, err
+			return nil, NewDecryptError("failed to read private key", err)
 		}
 
 		// If it is not managed then decrypt using the key provided
 		decryptedSecrets, err = i.decryptSecretsLocally(secrets, privatePEM)
 		if err != nil {
-			return nil, err
+			return nil, NewDecryptError("failed to decrypt secrets", err)
 		}
 	} else {
 		// If it is managed then decrypt using the managed secret decryption logic
 		decryptedSecrets, err = i.decryptManagedSecrets(secrets, appID, envTypeID)
 		if err != nil {
-			return nil, err
+			return nil, NewDecryptError("failed to reveal managed secrets", err)
 		}
 	}
 
-	injectedSecrets, err := i.injectToEnvironment(decryptedSecrets)
-	if err != nil {
-		return nil, err
-	}
-
-	return injectedSecrets, nil
+	return i.secretsToMap(decryptedSecrets), nil
 }
 
 func (i *injectSecretUseCase) getAllSecrets(appID, envTypeID string) ([]domain.Secret, error) {
@@ -69,12 +63,12 @@ func (i *injectSecretUseCase) getAllSecrets(appID, envTypeID string) ([]domain.S
 }
 
 func (i *injectSecretUseCase) decryptSecretsLocally(secrets []domain.Secret, privateKeyPEM string) ([]domain.Secret, error) {
-	for _, secret := range secrets {
+	for idx, secret := range secrets {
 		decryptedValue, err := utils.SmartDecrypt(secret.Value, privateKeyPEM)
 		if err != nil {
 			return nil, err
 		}
-		secret.Value = decryptedValue
+		secrets[idx].Value = decryptedValue
 	}
 	return secrets, nil
 }
@@ -89,8 +83,8 @@ func (i *injectSecretUseCase) extractPrivateKey(keyPath string) (string, error)
 
 func (i *injectSecretUseCase) decryptManagedSecrets(secrets []domain.Secret, appID, envTypeID string) ([]domain.Secret, error) {
 	keys := make([]string, len(secrets))
-	for i, secret := range secrets {
-		keys[i] = secret.Key
+	for idx, secret := range secrets {
+		keys[idx] = secret.Key
 	}
 
 	decryptedSecrets, err := i.secretService.RevelSecrets(appID, envTypeID, keys)
@@ -101,14 +95,12 @@ func (i *injectSecretUseCase) decryptManagedSecrets(secrets []domain.Secret, app
 	return decryptedSecrets, nil
 }
 
-func (i *injectSecretUseCase) injectToEnvironment(secrets []domain.Secret) (map[string]string, error) {
-	injectedSecrets := make(map[string]string)
+// secretsToMap returns the secrets by key, the caller decides where they end up
+func (i *injectSecretUseCase) secretsToMap(secrets []domain.Secret) map[string]string {
+	values := make(map[string]string, len(secrets))
 	for _, secret := range secrets {
-		if err := os.Setenv(secret.Key, secret.Value); err != nil {
-			return nil, err
-		}
-		injectedSecrets[secret.Key] = secret.Value
+		values[secret.Key] = secret.Value
 	}
 
-	return injectedSecrets, nil
+	return values
 }
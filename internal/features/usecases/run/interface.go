@@ -2,12 +2,14 @@ package run
 
 import (
 	"context"
+	"time"
 
 	"github.com/EnvSync-Cloud/envsync-cli/internal/domain"
+	"github.com/EnvSync-Cloud/envsync-cli/internal/services"
 )
 
 type ReadConfigUseCase interface {
-	Execute(context.Context) (*domain.SyncConfig, error)
+	Execute(context.Context, string, string) (*domain.SyncConfig, error)
 }
 
 type FetchAppUseCase interface {
@@ -20,8 +22,51 @@ type InjectEnvUseCase interface {
 
 type InjectSecretsUseCase interface {
 	Execute(context.Context) (map[string]string, error)
+	WriteFiles(secrets map[string]string, files map[string]string) (*SecretFiles, error)
+}
+
+// WatchUseCase reports changes of the remote variables, see WatchOptions
+type WatchUseCase interface {
+	Execute(context.Context, WatchOptions) <-chan EnvSnapshot
 }
 
 type RedactUseCase interface {
-	Execute(context.Context, []string, map[string]string) int
+	Execute(context.Context, ExecOptions) int
+}
+
+type RunProcessesUseCase interface {
+	Execute(context.Context, ProcessOptions) int
+}
+
+// ExecOptions describes the command run by RedactUseCase
+type ExecOptions struct {
+	// Args is the argv of the command
+	Args []string
+	// Env is the complete environment of the command, see BuildEnv
+	Env []string
+	// Values are hidden from the output of the command, keyed by variable name
+	Values map[string]string
+	// Redact configures how Values are found and replaced
+	Redact services.RedactOptions
+	// GracePeriod is how long the command gets to exit after a termination
+	// signal before it is killed
+	GracePeriod time.Duration
+	// TTY attaches the command to a pty. Otherwise stdout and stderr are
+	// separate pipes and stdin is passed through as is.
+	TTY bool
+}
+
+// ProcessOptions describes the processes run together by RunProcessesUseCase.
+// They share their environment and redaction settings.
+type ProcessOptions struct {
+	Processes []domain.Process
+	// Env is the complete environment of every process, see BuildEnv
+	Env []string
+	// Values are hidden from the output of the processes, keyed by variable name
+	Values map[string]string
+	// Redact configures how Values are found and replaced
+	Redact services.RedactOptions
+	// GracePeriod is how long a process gets to exit after a termination
+	// signal before it is killed
+	GracePeriod time.Duration
 }
@@ -0,0 +1,58 @@
+package run
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// prefixWriter writes every line to w with a prefix in front. Lines are
+// written whole while holding mu, so writers sharing mu never interleave
+// within a line. An unfinished line is held back until Close.
+type prefixWriter struct {
+	w      io.Writer
+	mu     *sync.Mutex
+	prefix []byte
+	line   []byte
+}
+
+func newPrefixWriter(w io.Writer, mu *sync.Mutex, prefix string) *prefixWriter {
+	return &prefixWriter{
+		w:      w,
+		mu:     mu,
+		prefix: []byte(prefix),
+	}
+}
+
+func (pw *prefixWriter) Write(p []byte) (int, error) {
+	pw.line = append(pw.line, p...)
+
+	for {
+		i := bytes.IndexByte(pw.line, '\n')
+		if i == -1 {
+			return len(p), nil
+		}
+		if err := pw.writeLine(pw.line[:i+1]); err != nil {
+			return 0, err
+		}
+		pw.line = pw.line[i+1:]
+	}
+}
+
+// Close writes the unfinished line, if any. The underlying writer is not closed.
+func (pw *prefixWriter) Close() error {
+	if len(pw.line) == 0 {
+		return nil
+	}
+	line := append(pw.line, '\n')
+	pw.line = nil
+	return pw.writeLine(line)
+}
+
+func (pw *prefixWriter) writeLine(line []byte) error {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+
+	_, err := pw.w.Write(append(pw.prefix[:len(pw.prefix):len(pw.prefix)], line...))
+	return err
+}
@@ -0,0 +1,148 @@
+package run
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/EnvSync-Cloud/envsync-cli/internal/domain"
+	"github.com/EnvSync-Cloud/envsync-cli/internal/presentation/style"
+	"github.com/EnvSync-Cloud/envsync-cli/internal/services"
+	"github.com/EnvSync-Cloud/envsync-cli/internal/utils"
+)
+
+// processColors tell the output of the processes apart, in turn
+var processColors = []lipgloss.Color{
+	style.InfoColor,
+	style.AccentColor,
+	style.PrimaryColor,
+	style.SecondaryColor,
+	style.SuccessColor,
+	style.ErrorColor,
+}
+
+type runProcessesUseCase struct{}
+
+func NewRunProcessesUseCase() RunProcessesUseCase {
+	return &runProcessesUseCase{}
+}
+
+// Execute starts every process through the shell and waits for the first one
+// to exit. The others are then stopped like on SIGTERM, and the exit status
+// of the first one is returned. All of them are stopped once ctx is done.
+func (uc *runProcessesUseCase) Execute(ctx context.Context, opts ProcessOptions) int {
+	if len(opts.Processes) == 0 {
+		fmt.Fprintf(os.Stderr, "No processes provided\n")
+		return ExitCodeConfig
+	}
+
+	// One redactor serves every stream of every process
+	redactor := services.NewRedactorServiceForEnv(opts.Values, opts.Redact)
+
+	width := 0
+	for _, p := range opts.Processes {
+		width = max(width, len(p.Name))
+	}
+
+	stopCtx, stopAll := context.WithCancel(ctx)
+	defer stopAll()
+
+	var output sync.Mutex
+	exits := make(chan processExit, len(opts.Processes))
+	for i, p := range opts.Processes {
+		color := processColors[i%len(processColors)]
+		prefix := lipgloss.NewStyle().Foreground(color).Render(fmt.Sprintf("%-*s |", width, p.Name)) + " "
+
+		go func() {
+			exits <- processExit{
+				name: p.Name,
+				code: uc.runProcess(stopCtx, p, opts, redactor, &output, prefix),
+			}
+		}()
+	}
+
+	first := <-exits
+	if len(opts.Processes) > 1 {
+		output.Lock()
+		fmt.Fprintf(os.Stderr, "envsync: %s exited with status %d, stopping the other processes\n", first.name, first.code)
+		output.Unlock()
+	}
+	stopAll()
+
+	for range len(opts.Processes) - 1 {
+		<-exits
+	}
+	return first.code
+}
+
+type processExit struct {
+	name string
+	code int
+}
+
+// runProcess runs one process with its stdout and stderr redacted and
+// prefixed, and returns its exit status
+func (uc *runProcessesUseCase) runProcess(
+	ctx context.Context,
+	p domain.Process,
+	opts ProcessOptions,
+	redactor services.RedactorService,
+	output *sync.Mutex,
+	prefix string,
+) int {
+	args := utils.ShellCommand(p.Command)
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Env = opts.Env
+	// The processes do not read the terminal, each gets a group of its own
+	setProcessGroup(cmd)
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating stdout pipe for %s: %v\n", p.Name, err)
+		return ExitCodeSetup
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating stderr pipe for %s: %v\n", p.Name, err)
+		return ExitCodeSetup
+	}
+
+	if err := cmd.Start(); err != nil {
+		startErr := NewStartError(args[0], err)
+		fmt.Fprintf(os.Stderr, "envsync: %v\n", startErr)
+		return startErr.ExitCode()
+	}
+
+	// Out of the foreground group, the processes only get the signals of
+	// the terminal through envsync
+	stopSignals := forwardSignals(ctx, cmd.Process, opts.GracePeriod)
+	defer stopSignals()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		w := newPrefixWriter(os.Stdout, output, prefix)
+		defer w.Close()
+		copyRedacted(w, stdout, redactor, services.RedactStreamStdout)
+	}()
+	go func() {
+		defer wg.Done()
+		w := newPrefixWriter(os.Stderr, output, prefix)
+		defer w.Close()
+		copyRedacted(w, stderr, redactor, services.RedactStreamStderr)
+	}()
+	wg.Wait()
+
+	err = cmd.Wait()
+	if cmd.ProcessState == nil {
+		fmt.Fprintf(os.Stderr, "Error waiting for %s: %v\n", p.Name, err)
+		return ExitCodeSetup
+	}
+	return ExitStatus(cmd.ProcessState)
+}
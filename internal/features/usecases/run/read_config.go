@@ -2,27 +2,45 @@ package run
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/EnvSync-Cloud/envsync-cli/internal/domain"
 	"github.com/EnvSync-Cloud/envsync-cli/internal/services"
 )
 
 type readConfigUseCase struct {
-	syncService services.SyncService
+	envTypeService services.EnvTypeService
 }
 
 func NewReadConfigUseCase() ReadConfigUseCase {
-	syncService := services.NewSyncService()
+	envTypeService := services.NewEnvTypeService()
 	return &readConfigUseCase{
-		syncService: syncService,
+		envTypeService: envTypeService,
 	}
 }
 
-func (r *readConfigUseCase) Execute(ctx context.Context) (*domain.SyncConfig, error) {
-	config, err := r.syncService.ReadConfigData()
+// Execute reads the project configuration at configPath. When envType is set,
+// it is resolved by name or ID and replaces the configured environment type.
+func (r *readConfigUseCase) Execute(ctx context.Context, configPath, envType string) (*domain.SyncConfig, error) {
+	config, err := services.ReadSyncConfig(configPath)
 	if err != nil {
-		return nil, err
+		return nil, NewConfigError("failed to read configuration file", err)
 	}
 
+	if envType == "" {
+		return &config, nil
+	}
+
+	envTypes, err := r.envTypeService.GetEnvTypesByAppID(config.AppID)
+	if err != nil {
+		return nil, NewFetchError("failed to fetch environment types", err)
+	}
+
+	selected, ok := domain.FindEnvType(envTypes, envType)
+	if !ok {
+		return nil, NewConfigError(fmt.Sprintf("environment type %q not found", envType), nil)
+	}
+	config.EnvTypeID = selected.ID
+
 	return &config, nil
 }
@@ -6,153 +6,252 @@ import (
 	"io"
 	"os"
 	"os/exec"
-	"os/signal"
-	"regexp"
-	"strings"
-	"syscall"
+	"sync"
 	"time"
 
 	"github.com/aymanbagabas/go-pty"
+	"github.com/charmbracelet/x/term"
+
+	"github.com/EnvSync-Cloud/envsync-cli/internal/services"
+	"github.com/EnvSync-Cloud/envsync-cli/internal/utils"
 )
 
-type redactUseCase struct{}
+type redactUseCase struct {
+	stdinOnce sync.Once
+	// stdin delivers the input of envsync, see stdinReader
+	stdin <-chan []byte
+}
 
 func NewRedactor() RedactUseCase {
 	return &redactUseCase{}
 }
 
-func (uc *redactUseCase) Execute(ctx context.Context, args []string, envData map[string]string) int {
-	if len(args) == 0 {
+// Execute runs the command and returns its exit status. The command is
+// stopped once ctx is done.
+func (uc *redactUseCase) Execute(ctx context.Context, opts ExecOptions) int {
+	if len(opts.Args) == 0 {
 		fmt.Fprintf(os.Stderr, "No command provided\n")
-		return 1
+		return ExitCodeConfig
+	}
+
+	// One redactor serves every stream of the command
+	redactor := services.NewRedactorServiceForEnv(opts.Values, opts.Redact)
+
+	if !opts.TTY {
+		return uc.executePiped(ctx, opts, redactor)
+	}
+	return uc.executePTY(ctx, opts, redactor)
+}
+
+// executePiped runs the command with separate stdout and stderr pipes, each
+// redacted on its own. Stdin is handed to the command directly so nothing is
+// buffered in between.
+func (uc *redactUseCase) executePiped(ctx context.Context, opts ExecOptions, redactor services.RedactorService) int {
+	args := opts.Args
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Env = opts.Env
+	cmd.Stdin = os.Stdin
+
+	// A command reading the terminal of envsync stays in its foreground
+	// process group, where it can read the terminal and the terminal signals
+	// every process it starts. Otherwise it gets a group of its own.
+	sharesTerminal := term.IsTerminal(os.Stdin.Fd())
+	if !sharesTerminal {
+		setProcessGroup(cmd)
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating stdout pipe: %v\n", err)
+		return ExitCodeSetup
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating stderr pipe: %v\n", err)
+		return ExitCodeSetup
+	}
+
+	if err := cmd.Start(); err != nil {
+		startErr := NewStartError(args[0], err)
+		fmt.Fprintf(os.Stderr, "envsync: %v\n", startErr)
+		return startErr.ExitCode()
+	}
+
+	// A command sharing the terminal gets the signals of its keys straight
+	// from it
+	var skip []os.Signal
+	if sharesTerminal {
+		skip = terminalSignals
+	}
+	stopSignals := forwardSignals(ctx, cmd.Process, opts.GracePeriod, skip...)
+	defer stopSignals()
+
+	// Both pipes have to be drained before Wait closes them
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		copyRedacted(os.Stdout, stdout, redactor, services.RedactStreamStdout)
+	}()
+	go func() {
+		defer wg.Done()
+		copyRedacted(os.Stderr, stderr, redactor, services.RedactStreamStderr)
+	}()
+	wg.Wait()
+
+	err = cmd.Wait()
+	if cmd.ProcessState == nil {
+		fmt.Fprintf(os.Stderr, "Error waiting for command: %v\n", err)
+		return ExitCodeSetup
+	}
+	return ExitStatus(cmd.ProcessState)
+}
+
+// copyRedacted copies the output of the command to w with the values redacted.
+// Output is drained even when w fails so the command never blocks on a full pipe.
+func copyRedacted(w io.Writer, r io.Reader, redactor services.RedactorService, stream string) {
+	output := redactor.NewWriter(w, stream)
+	defer output.Close()
+
+	buffer := make([]byte, 4096)
+	for {
+		n, err := r.Read(buffer)
+		if n > 0 {
+			if _, writeErr := output.Write(buffer[:n]); writeErr != nil {
+				io.Copy(io.Discard, r)
+				return
+			}
+		}
+		if err != nil {
+			return
+		}
 	}
+}
+
+// executePTY runs the command attached to a pty, stdout and stderr share it
+func (uc *redactUseCase) executePTY(ctx context.Context, opts ExecOptions, redactor services.RedactorService) int {
+	args := opts.Args
 
 	// Create a new PTY
 	ptyMaster, err := pty.New()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error creating PTY: %v\n", err)
-		return 1
+		return ExitCodeSetup
 	}
 	defer ptyMaster.Close()
 
 	// Create the command using PTY
 	cmd := ptyMaster.Command(args[0], args[1:]...)
 
-	// Set environment variables to force color output
-	cmd.Env = append(os.Environ(),
-		"FORCE_COLOR=1",
-		"CLICOLOR_FORCE=1",
-		"TERM=xterm-256color",
-	)
+	cmd.Env = opts.Env
 
 	// Start the command
 	if err := cmd.Start(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error starting command: %v\n", err)
-		return 1
+		startErr := NewStartError(args[0], err)
+		fmt.Fprintf(os.Stderr, "envsync: %v\n", startErr)
+		return startErr.ExitCode()
 	}
 
-	// Handle interrupt signals
-	cancelCtx, cancel := context.WithCancel(context.Background())
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	// The command runs in a session of its own, every signal is forwarded
+	stopSignals := forwardSignals(ctx, cmd.Process, opts.GracePeriod)
+	defer stopSignals()
 
-	// Handle signals in a goroutine
-	go func() {
-		<-sigChan
-		fmt.Fprintf(os.Stderr, "\nReceived interrupt signal, terminating command...\n")
-		cancel()
-		// Forward signal to the child process
-		if cmd.Process != nil {
-			cmd.Process.Signal(os.Interrupt)
-			// Give the process a chance to handle the signal gracefully
-			time.Sleep(100 * time.Millisecond)
-			// If still running, force kill
-			if cmd.ProcessState == nil || !cmd.ProcessState.Exited() {
-				cmd.Process.Kill()
-			}
-		}
-	}()
+	stopResize := watchResize(ptyMaster)
+	defer stopResize()
+
+	// Key presses reach the command as they are, its pty handles echo,
+	// line editing and Ctrl-C. The terminal is restored however envsync ends.
+	restore, err := utils.MakeStdinRaw()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "envsync: failed to put the terminal in raw mode: %v\n", err)
+	}
+	defer restore()
+
+	cancelCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
 	// Channels to signal completion
 	outputDone := make(chan int, 1)
 	cmdDone := make(chan int, 1)
 
 	// Handle stdin in a separate goroutine
-	go uc.handleStdin(cancelCtx, ptyMaster)
+	go func() {
+		defer utils.RestoreOnPanic(restore)
+		uc.handleStdin(cancelCtx, ptyMaster, uc.stdinReader())
+	}()
 
 	// Handle stdout/stderr processing
-	go uc.handleOutput(cancelCtx, ptyMaster, outputDone, envData)
+	go func() {
+		defer utils.RestoreOnPanic(restore)
+		uc.handleOutput(cancelCtx, ptyMaster, outputDone, redactor)
+	}()
 
-	// Wait for command completion or context cancellation
+	// Wait for command completion
 	go func() {
-		exitCode := 0
-		if err := cmd.Wait(); err != nil {
-			if exitError, ok := err.(*exec.ExitError); ok {
-				exitCode = exitError.ExitCode()
-			} else {
-				exitCode = 1
-			}
+		err := cmd.Wait()
+		if cmd.ProcessState == nil {
+			fmt.Fprintf(os.Stderr, "Error waiting for command: %v\n", err)
+			cmdDone <- ExitCodeSetup
+			return
 		}
-		cmdDone <- exitCode
+		cmdDone <- ExitStatus(cmd.ProcessState)
 	}()
 
-	// Wait for completion or cancellation
+	exitCode := <-cmdDone
+
+	// Wait for output processing to finish with timeout
 	select {
-	case exitCode := <-cmdDone:
-		// Wait for output processing to finish with timeout
-		select {
-		case <-outputDone:
-		case <-time.After(1 * time.Second):
-			// Timeout waiting for output processing
-		}
-		cancel()
-		return exitCode
-	case <-cancelCtx.Done():
-		// Wait for command to finish with timeout
-		select {
-		case exitCode := <-cmdDone:
-			return exitCode
-		case <-time.After(2 * time.Second):
-			// Force kill if still running
-			if cmd.Process != nil {
-				cmd.Process.Kill()
-			}
-			return 1
-		}
+	case <-outputDone:
+	case <-time.After(1 * time.Second):
+		// Timeout waiting for output processing
 	}
+	return exitCode
 }
 
-func (uc *redactUseCase) handleStdin(ctx context.Context, ptyMaster pty.Pty) {
-	// Use a goroutine to handle stdin reading without blocking
-	stdinChan := make(chan []byte, 1)
-	go func() {
-		buffer := make([]byte, 1024)
-		for {
-			n, err := os.Stdin.Read(buffer)
-			if err != nil {
-				if err != io.EOF {
-					fmt.Fprintf(os.Stderr, "Error reading stdin: %v\n", err)
+// stdinReader returns the input of envsync, read by a single goroutine for
+// as long as envsync runs. A read of os.Stdin cannot be interrupted, so a
+// reader per command would outlive it and take the keys meant for the next
+// one when --watch restarts the command.
+func (uc *redactUseCase) stdinReader() <-chan []byte {
+	uc.stdinOnce.Do(func() {
+		stdin := make(chan []byte)
+		uc.stdin = stdin
+
+		go func() {
+			defer close(stdin)
+			buffer := make([]byte, 1024)
+			for {
+				n, err := os.Stdin.Read(buffer)
+				if n > 0 {
+					// Make a copy of the buffer to send through channel
+					data := make([]byte, n)
+					copy(data, buffer[:n])
+					stdin <- data
 				}
-				return
-			}
-			if n > 0 {
-				// Make a copy of the buffer to send through channel
-				data := make([]byte, n)
-				copy(data, buffer[:n])
-				select {
-				case stdinChan <- data:
-				case <-ctx.Done():
+				if err != nil {
+					if err != io.EOF {
+						fmt.Fprintf(os.Stderr, "Error reading stdin: %v\n", err)
+					}
 					return
 				}
 			}
-		}
-	}()
+		}()
+	})
+	return uc.stdin
+}
 
+// handleStdin writes the input of envsync to the pty until ctx is done.
+// Input read after that is kept for the next command.
+func (uc *redactUseCase) handleStdin(ctx context.Context, ptyMaster io.Writer, stdin <-chan []byte) {
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case data := <-stdinChan:
+		case data, ok := <-stdin:
+			if !ok {
+				return
+			}
 			_, writeErr := ptyMaster.Write(data)
 			if writeErr != nil {
 				fmt.Fprintf(os.Stderr, "Error writing to PTY: %v\n", writeErr)
@@ -162,12 +261,15 @@ func (uc *redactUseCase) handleStdin(ctx context.Context, ptyMaster pty.Pty) {
 	}
 }
 
-func (uc *redactUseCase) handleOutput(ctx context.Context, ptyMaster pty.Pty, done chan<- int, envData map[string]string) {
+func (uc *redactUseCase) handleOutput(ctx context.Context, ptyMaster pty.Pty, done chan<- int, redactor services.RedactorService) {
 	buffer := make([]byte, 4096)
 	defer func() {
 		done <- 0
 	}()
 
+	output := redactor.NewWriter(os.Stdout, services.RedactStreamPTY)
+	defer output.Close()
+
 	// Use a goroutine to handle PTY reading without blocking
 	outputChan := make(chan []byte, 1)
 	errorChan := make(chan error, 1)
@@ -200,12 +302,8 @@ func (uc *redactUseCase) handleOutput(ctx context.Context, ptyMaster pty.Pty, do
 		case <-ctx.Done():
 			return
 		case data := <-outputChan:
-			// Process and redact the output
-			text := string(data)
-			redactedText := uc.processAndRedactText(text, envData)
-
-			// Write the redacted content to stdout
-			_, writeErr := os.Stdout.Write([]byte(redactedText))
+			// Redact the output and write it to stdout
+			_, writeErr := output.Write(data)
 			if writeErr != nil {
 				fmt.Fprintf(os.Stderr, "Error writing output: %v\n", writeErr)
 				return
@@ -220,44 +318,3 @@ func (uc *redactUseCase) handleOutput(ctx context.Context, ptyMaster pty.Pty, do
 		}
 	}
 }
-
-func (uc *redactUseCase) processAndRedactText(text string, envData map[string]string) string {
-	if len(text) == 0 {
-		return text
-	}
-
-	return uc.redactWithRegex(text, envData)
-}
-
-// redactWithRegex implements regex-based redaction similar to the JavaScript implementation
-func (uc *redactUseCase) redactWithRegex(text string, envData map[string]string) string {
-	if len(envData) == 0 {
-		return text
-	}
-
-	output := text
-
-	// Create redaction patterns for each env var (similar to JavaScript implementation)
-	for _, value := range envData {
-		if value == "" {
-			continue
-		}
-
-		// Escape special regex characters in the value
-		escapedValue := regexp.QuoteMeta(value)
-
-		// Create regex pattern
-		pattern, err := regexp.Compile(escapedValue)
-		if err != nil {
-			// If regex compilation fails, fall back to simple string replacement
-			output = strings.ReplaceAll(output, value, "[REDACTED]")
-			continue
-		}
-
-		// Replace all occurrences with the redacted message
-		replacement := "[REDACTED]"
-		output = pattern.ReplaceAllString(output, replacement)
-	}
-
-	return output
-}
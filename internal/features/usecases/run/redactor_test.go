@@ -0,0 +1,174 @@
+package run
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/EnvSync-Cloud/envsync-cli/internal/utils"
+)
+
+// TestHelperProcess is the command run by the tests of the piped path, it
+// does nothing unless started by them
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("ENVSYNC_HELPER_PROCESS") != "1" {
+		return
+	}
+
+	// The value is split across writes well within the flush delay, and
+	// stdin is echoed back
+	fmt.Fprint(os.Stdout, "token=supers")
+	time.Sleep(5 * time.Millisecond)
+	fmt.Fprint(os.Stdout, "ecret123\n")
+	fmt.Fprint(os.Stderr, "error: supersecret123 rejected\n")
+
+	input, _ := io.ReadAll(os.Stdin)
+	fmt.Fprintf(os.Stdout, "stdin: %s\n", input)
+	os.Exit(3)
+}
+
+// capture replaces f, os.Stdout or os.Stderr, with a pipe and returns a
+// function restoring it and returning what was written
+func capture(t *testing.T, f **os.File) func() string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	original := *f
+	*f = w
+
+	var output bytes.Buffer
+	done := make(chan struct{})
+	go func() {
+		io.Copy(&output, r)
+		close(done)
+	}()
+
+	return func() string {
+		*f = original
+		w.Close()
+		<-done
+		r.Close()
+		return output.String()
+	}
+}
+
+func TestExecutePiped(t *testing.T) {
+	stdinR, stdinW, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	stdin := os.Stdin
+	os.Stdin = stdinR
+	defer func() {
+		os.Stdin = stdin
+		stdinR.Close()
+	}()
+	stdinW.WriteString("hello from stdin")
+	stdinW.Close()
+
+	stdout := capture(t, &os.Stdout)
+	stderr := capture(t, &os.Stderr)
+
+	// Output that is not a terminal runs the command without a pty
+	interactive := utils.IsInteractive()
+
+	code := NewRedactor().Execute(context.Background(), ExecOptions{
+		Args:   []string{os.Args[0], "-test.run=^TestHelperProcess$"},
+		Env:    append(os.Environ(), "ENVSYNC_HELPER_PROCESS=1"),
+		Values: map[string]string{"TOKEN": "supersecret123"},
+		TTY:    interactive,
+	})
+
+	errOutput := stderr()
+	output := stdout()
+
+	if interactive {
+		t.Errorf("Expected a piped stdout not to be interactive")
+	}
+	if code != 3 {
+		t.Errorf("Expected exit code 3, got %d", code)
+	}
+	if !strings.Contains(output, "token=[REDACTED]\n") || !strings.Contains(output, "stdin: hello from stdin\n") {
+		t.Errorf("Unexpected stdout %q", output)
+	}
+	if errOutput != "error: [REDACTED] rejected\n" {
+		t.Errorf("Unexpected stderr %q", errOutput)
+	}
+	if strings.Contains(output+errOutput, "supersecret123") {
+		t.Errorf("The value leaked: %q %q", output, errOutput)
+	}
+}
+
+// chanWriter sends every write to a channel
+type chanWriter chan string
+
+func (w chanWriter) Write(p []byte) (int, error) {
+	w <- string(p)
+	return len(p), nil
+}
+
+func TestHandleStdinGoesToTheCurrentCommand(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	stdin := os.Stdin
+	os.Stdin = r
+	t.Cleanup(func() {
+		os.Stdin = stdin
+		r.Close()
+	})
+
+	uc := &redactUseCase{}
+
+	// The first command is restarted before anything is typed
+	first := make(chanWriter, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		uc.handleStdin(ctx, first, uc.stdinReader())
+		close(done)
+	}()
+	cancel()
+	<-done
+
+	second := make(chanWriter, 1)
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	done = make(chan struct{})
+	go func() {
+		uc.handleStdin(ctx, second, uc.stdinReader())
+		close(done)
+	}()
+
+	if _, err := w.Write([]byte("y\n")); err != nil {
+		t.Fatalf("Failed to write to stdin: %v", err)
+	}
+	select {
+	case got := <-second:
+		if got != "y\n" {
+			t.Errorf("Expected %q, got %q", "y\n", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("The input did not reach the current command")
+	}
+	if len(first) != 0 {
+		t.Errorf("Expected nothing for the stopped command, got %q", <-first)
+	}
+
+	// End of input stops the reader along with the command
+	w.Close()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("handleStdin did not return at the end of input")
+	}
+}
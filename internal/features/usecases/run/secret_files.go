@@ -0,0 +1,129 @@
+package run
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// SecretFiles are secrets written to files for tools that only read
+// credentials from a file
+type SecretFiles struct {
+	// Dir is the private directory holding the files
+	Dir string
+	// Paths are the files by secret key
+	Paths map[string]string
+}
+
+// ParseFileSecrets parses --file-secret KEY[=NAME] flags into file names by
+// key. The file is named after the key unless NAME is given.
+func ParseFileSecrets(values []string) (map[string]string, error) {
+	files := make(map[string]string, len(values))
+	for _, kv := range values {
+		key, name, ok := strings.Cut(kv, "=")
+		if key == "" || (ok && name == "") {
+			return nil, NewConfigError(fmt.Sprintf("invalid --file-secret %q, expected KEY or KEY=NAME", kv), nil)
+		}
+		files[key] = name
+	}
+	return files, nil
+}
+
+// WriteFiles writes the secrets listed in files, file names by key, to a new
+// private directory readable by the current user only. An empty name uses
+// the key. The caller removes the files with Remove once the command exits.
+func (i *injectSecretUseCase) WriteFiles(secrets map[string]string, files map[string]string) (*SecretFiles, error) {
+	keys := slices.Sorted(maps.Keys(files))
+
+	names := make(map[string]string, len(files))
+	for _, key := range keys {
+		if _, ok := secrets[key]; !ok {
+			return nil, NewConfigError(fmt.Sprintf("secret %s to write to a file not found", key), nil)
+		}
+
+		name := files[key]
+		if name == "" {
+			name = key
+		}
+		if name != filepath.Base(name) || name == "." || name == ".." {
+			return nil, NewConfigError(fmt.Sprintf("invalid file name %q for secret %s, expected a name without directories", name, key), nil)
+		}
+		if other, ok := names[name]; ok {
+			return nil, NewConfigError(fmt.Sprintf("secrets %s and %s are written to the same file %s", other, key, name), nil)
+		}
+		names[name] = key
+	}
+
+	// MkdirTemp creates the directory with 0700
+	dir, err := os.MkdirTemp("", "envsync-secrets-")
+	if err != nil {
+		return nil, NewSetupError("failed to create the secret files directory", err)
+	}
+
+	sf := &SecretFiles{Dir: dir, Paths: make(map[string]string, len(files))}
+	for name, key := range names {
+		path := filepath.Join(dir, name)
+		if err := writeSecretFile(path, secrets[key]); err != nil {
+			sf.Remove()
+			return nil, NewSetupError("failed to write secret "+key+" to a file", err)
+		}
+		sf.Paths[key] = path
+	}
+
+	return sf, nil
+}
+
+// Remove overwrites every file with zeros before deleting it along with the
+// directory, so the secrets do not linger on disk
+func (f *SecretFiles) Remove() error {
+	var errs []error
+	for _, path := range f.Paths {
+		if err := wipeFile(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	if err := os.RemoveAll(f.Dir); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+func writeSecretFile(path, value string) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.WriteString(value); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func wipeFile(path string) error {
+	file, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err == nil {
+		_, err = file.Write(make([]byte, info.Size()))
+	}
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.Remove(path)
+}
@@ -0,0 +1,65 @@
+package run
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"slices"
+	"time"
+)
+
+// DefaultKillGracePeriod is how long the command gets to exit after a
+// termination signal before it is killed
+const DefaultKillGracePeriod = 10 * time.Second
+
+// forwardSignals relays the signals envsync receives to process, as they
+// are, and to its children when it leads a process group, see
+// setProcessGroup. Signals in skip reach the command without help, for
+// example from the terminal, and are not sent again. After the first
+// terminating signal the process has grace to exit before it is killed. Once
+// ctx is done the process is asked to stop with stopSignal, with the same
+// grace period. The returned function stops forwarding and must be called
+// once the process has exited.
+func forwardSignals(ctx context.Context, process *os.Process, grace time.Duration, skip ...os.Signal) (stop func()) {
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, forwardedSignals...)
+
+	done := make(chan struct{})
+	go func() {
+		var kill *time.Timer
+		startKill := func() {
+			if kill == nil {
+				kill = time.AfterFunc(grace, func() {
+					sendSignal(process, os.Kill)
+				})
+			}
+		}
+
+		ctxDone := ctx.Done()
+		for {
+			select {
+			case <-done:
+				if kill != nil {
+					kill.Stop()
+				}
+				return
+			case <-ctxDone:
+				ctxDone = nil
+				sendSignal(process, stopSignal)
+				startKill()
+			case sig := <-sigChan:
+				if !slices.Contains(skip, sig) {
+					sendSignal(process, sig)
+				}
+				if isTerminating(sig) {
+					startKill()
+				}
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(sigChan)
+		close(done)
+	}
+}
@@ -0,0 +1,88 @@
+//go:build !windows
+
+package run
+
+import (
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+
+	"github.com/aymanbagabas/go-pty"
+	"github.com/charmbracelet/x/term"
+)
+
+// forwardedSignals are relayed to the command
+var forwardedSignals = []os.Signal{
+	syscall.SIGINT,
+	syscall.SIGTERM,
+	syscall.SIGHUP,
+	syscall.SIGQUIT,
+	syscall.SIGUSR1,
+	syscall.SIGUSR2,
+}
+
+// terminalSignals are sent by the terminal to its whole foreground process
+// group, a command sharing the terminal of envsync gets them directly
+var terminalSignals = []os.Signal{syscall.SIGINT, syscall.SIGQUIT}
+
+// stopSignal asks the command to exit when envsync stops it on its own
+var stopSignal os.Signal = syscall.SIGTERM
+
+// isTerminating reports whether sig asks the command to exit
+func isTerminating(sig os.Signal) bool {
+	switch sig {
+	case syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGQUIT:
+		return true
+	}
+	return false
+}
+
+// setProcessGroup puts the command in a process group of its own, so the
+// processes it starts are signalled and killed along with it rather than
+// outliving it and holding its output open
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// sendSignal signals the whole group of process when it leads one, as after
+// setProcessGroup or in a pty, and process alone otherwise
+func sendSignal(process *os.Process, sig os.Signal) {
+	if s, ok := sig.(syscall.Signal); ok {
+		if pgid, err := syscall.Getpgid(process.Pid); err == nil && pgid == process.Pid {
+			syscall.Kill(-pgid, s)
+			return
+		}
+	}
+	process.Signal(sig)
+}
+
+// watchResize keeps the size of the pty in line with the terminal of envsync
+// until the returned function is called
+func watchResize(ptyMaster pty.Pty) (stop func()) {
+	resize := func() {
+		if width, height, err := term.GetSize(os.Stdout.Fd()); err == nil {
+			ptyMaster.Resize(width, height)
+		}
+	}
+	resize()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGWINCH)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-sigChan:
+				resize()
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(sigChan)
+		close(done)
+	}
+}
@@ -0,0 +1,130 @@
+//go:build !windows
+
+package run
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/EnvSync-Cloud/envsync-cli/internal/domain"
+)
+
+// waitStopped cancels the command started by execute and fails unless it
+// returns within a few seconds, grandchildren included
+func waitStopped(t *testing.T, execute func(ctx context.Context) int) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan int, 1)
+	go func() {
+		done <- execute(ctx)
+	}()
+
+	// Give the shell time to start its child
+	time.Sleep(300 * time.Millisecond)
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("The command was not stopped along with the processes it started")
+	}
+}
+
+func TestStopReachesGrandchildrenOfProcesses(t *testing.T) {
+	t.Setenv("SHELL", "/bin/sh")
+
+	uc := NewRunProcessesUseCase()
+	waitStopped(t, func(ctx context.Context) int {
+		return uc.Execute(ctx, ProcessOptions{
+			Processes:   []domain.Process{{Name: "web", Command: "sleep 100 & wait"}},
+			GracePeriod: time.Second,
+		})
+	})
+}
+
+func TestStopReachesGrandchildrenOfPipedCommand(t *testing.T) {
+	uc := NewRedactor()
+	waitStopped(t, func(ctx context.Context) int {
+		return uc.Execute(ctx, ExecOptions{
+			Args:        []string{"/bin/sh", "-c", "sleep 100 & wait"},
+			GracePeriod: time.Second,
+		})
+	})
+}
+
+// TestHelperProcessIgnoringTerm is a command that reports SIGTERM and keeps
+// running, it does nothing unless started by the grace period test
+func TestHelperProcessIgnoringTerm(t *testing.T) {
+	if os.Getenv("ENVSYNC_HELPER_PROCESS") != "1" {
+		return
+	}
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGTERM)
+	fmt.Println("ready")
+	for sig := range sigChan {
+		fmt.Println(sig)
+	}
+}
+
+func TestStopKillsAfterGracePeriod(t *testing.T) {
+	const grace = 500 * time.Millisecond
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestHelperProcessIgnoringTerm$")
+	cmd.Env = append(os.Environ(), "ENVSYNC_HELPER_PROCESS=1")
+	setProcessGroup(cmd)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Failed to start the helper: %v", err)
+	}
+	defer cmd.Process.Kill()
+
+	lines := bufio.NewScanner(stdout)
+	if !lines.Scan() || lines.Text() != "ready" {
+		t.Fatalf("The helper did not start: %q", lines.Text())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stop := forwardSignals(ctx, cmd.Process, grace)
+	defer stop()
+
+	stopped := time.Now()
+	cancel()
+
+	// The command is asked to stop first
+	if !lines.Scan() || lines.Text() != syscall.SIGTERM.String() {
+		t.Fatalf("Expected the helper to get SIGTERM, got %q", lines.Text())
+	}
+
+	// and killed once the grace period is over
+	exited := make(chan struct{})
+	go func() {
+		cmd.Wait()
+		close(exited)
+	}()
+	select {
+	case <-exited:
+	case <-time.After(grace + 5*time.Second):
+		t.Fatal("The helper was not killed after the grace period")
+	}
+
+	if elapsed := time.Since(stopped); elapsed < grace {
+		t.Errorf("Expected the helper to be killed after %v, got %v", grace, elapsed)
+	}
+	if got := ExitStatus(cmd.ProcessState); got != 128+int(syscall.SIGKILL) {
+		t.Errorf("Expected the helper to be killed by SIGKILL, got exit status %d", got)
+	}
+}
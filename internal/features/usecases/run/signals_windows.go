@@ -0,0 +1,46 @@
+//go:build windows
+
+package run
+
+import (
+	"os"
+	"os/exec"
+
+	"github.com/aymanbagabas/go-pty"
+	"github.com/charmbracelet/x/term"
+)
+
+// forwardedSignals are relayed to the command
+var forwardedSignals = []os.Signal{os.Interrupt}
+
+// terminalSignals reach the command through the console it shares with envsync
+var terminalSignals = []os.Signal{os.Interrupt}
+
+// stopSignal kills the command, Windows has no signal asking it to exit
+var stopSignal = os.Kill
+
+// isTerminating reports whether sig asks the command to exit
+func isTerminating(sig os.Signal) bool {
+	return sig == os.Interrupt
+}
+
+// sendSignal only delivers os.Kill, Windows cannot deliver other signals to
+// another process. A command sharing the console gets Ctrl+C from it, any
+// other is killed once the grace period ends.
+func sendSignal(process *os.Process, sig os.Signal) {
+	if sig == os.Kill {
+		process.Kill()
+	}
+}
+
+// setProcessGroup does nothing, Windows can only kill the command itself
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// watchResize sizes the pty like the console of envsync. Windows has no
+// resize signal, so later changes are not followed.
+func watchResize(ptyMaster pty.Pty) (stop func()) {
+	if width, height, err := term.GetSize(os.Stdout.Fd()); err == nil {
+		ptyMaster.Resize(width, height)
+	}
+	return func() {}
+}
@@ -0,0 +1,155 @@
+package run
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"time"
+)
+
+// DefaultWatchInterval is how often --watch checks the remote variables
+const DefaultWatchInterval = 30 * time.Second
+
+// EnvSnapshot is the state of the remote variables at one point in time
+type EnvSnapshot struct {
+	Env     map[string]string
+	Secrets map[string]string
+}
+
+// Values returns the variables and secrets in one map, secrets win like they
+// do in the environment of the command
+func (s EnvSnapshot) Values() map[string]string {
+	values := maps.Clone(s.Env)
+	if values == nil {
+		values = make(map[string]string, len(s.Secrets))
+	}
+	maps.Copy(values, s.Secrets)
+	return values
+}
+
+// EnvChanges lists the keys that differ between two snapshots, sorted
+type EnvChanges struct {
+	Added   []string
+	Updated []string
+	Removed []string
+}
+
+// IsEmpty returns true if nothing changed
+func (c EnvChanges) IsEmpty() bool {
+	return len(c.Added) == 0 && len(c.Updated) == 0 && len(c.Removed) == 0
+}
+
+// DiffSnapshots returns what changed from before to after
+func DiffSnapshots(before, after EnvSnapshot) EnvChanges {
+	oldValues, newValues := before.Values(), after.Values()
+
+	var changes EnvChanges
+	for key, value := range newValues {
+		old, ok := oldValues[key]
+		switch {
+		case !ok:
+			changes.Added = append(changes.Added, key)
+		case old != value:
+			changes.Updated = append(changes.Updated, key)
+		}
+	}
+	for key := range oldValues {
+		if _, ok := newValues[key]; !ok {
+			changes.Removed = append(changes.Removed, key)
+		}
+	}
+
+	slices.Sort(changes.Added)
+	slices.Sort(changes.Updated)
+	slices.Sort(changes.Removed)
+	return changes
+}
+
+// WatchOptions describes what WatchUseCase polls
+type WatchOptions struct {
+	// Interval is the time between two checks
+	Interval time.Duration
+	// Current is the snapshot the command runs with
+	Current EnvSnapshot
+	// Secrets polls the secrets as well as the variables
+	Secrets bool
+}
+
+type watchUseCase struct {
+	injectEnv     InjectEnvUseCase
+	injectSecrets InjectSecretsUseCase
+}
+
+func NewWatchUseCase(injectEnv InjectEnvUseCase, injectSecrets InjectSecretsUseCase) WatchUseCase {
+	return &watchUseCase{
+		injectEnv:     injectEnv,
+		injectSecrets: injectSecrets,
+	}
+}
+
+// Execute polls the remote variables until ctx is done and sends every
+// snapshot that differs from the previous one. Failed checks are reported on
+// stderr and retried at the next interval. The channel is closed once ctx is
+// done.
+func (uc *watchUseCase) Execute(ctx context.Context, opts WatchOptions) <-chan EnvSnapshot {
+	changes := make(chan EnvSnapshot)
+
+	go func() {
+		defer close(changes)
+
+		ticker := time.NewTicker(opts.Interval)
+		defer ticker.Stop()
+
+		current := opts.Current.Values()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			snapshot, err := uc.poll(ctx, opts.Secrets)
+			if err != nil {
+				if ctx.Err() == nil {
+					fmt.Fprintf(os.Stderr, "envsync: failed to check for changes: %v\n", err)
+				}
+				continue
+			}
+
+			values := snapshot.Values()
+			if maps.Equal(values, current) {
+				continue
+			}
+
+			select {
+			case changes <- snapshot:
+				current = values
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return changes
+}
+
+func (uc *watchUseCase) poll(ctx context.Context, secrets bool) (EnvSnapshot, error) {
+	var snapshot EnvSnapshot
+
+	env, err := uc.injectEnv.Execute(ctx)
+	if err != nil {
+		return snapshot, err
+	}
+	snapshot.Env = env
+
+	if secrets {
+		snapshot.Secrets, err = uc.injectSecrets.Execute(ctx)
+		if err != nil {
+			return snapshot, err
+		}
+	}
+
+	return snapshot, nil
+}
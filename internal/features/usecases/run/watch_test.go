@@ -0,0 +1,111 @@
+package run
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/EnvSync-Cloud/envsync-cli/internal/repository/requests"
+	"github.com/EnvSync-Cloud/envsync-cli/internal/repository/responses"
+)
+
+// fakeEnvRepository serves the variables it holds, or err when set
+type fakeEnvRepository struct {
+	mu  sync.Mutex
+	env map[string]string
+	err error
+}
+
+func (r *fakeEnvRepository) set(env map[string]string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.env = env
+	r.err = err
+}
+
+func (r *fakeEnvRepository) GetAllEnv() ([]responses.EnvironmentVariable, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	var env []responses.EnvironmentVariable
+	for key, value := range r.env {
+		env = append(env, responses.EnvironmentVariable{Key: key, Value: value})
+	}
+	return env, nil
+}
+
+func (r *fakeEnvRepository) BatchCreateEnv(requests.BatchSyncEnvRequest) error { return nil }
+func (r *fakeEnvRepository) BatchUpdateEnv(requests.BatchSyncEnvRequest) error { return nil }
+func (r *fakeEnvRepository) BatchDeleteEnv(requests.BatchDeleteRequest) error  { return nil }
+
+func TestWatchReportsChanges(t *testing.T) {
+	current := map[string]string{"A": "1", "B": "2"}
+	repo := &fakeEnvRepository{env: current}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	watch := NewWatchUseCase(NewInjectEnvFromRepository(repo), nil)
+	changes := watch.Execute(ctx, WatchOptions{
+		Interval: 5 * time.Millisecond,
+		Current:  EnvSnapshot{Env: current},
+	})
+
+	// Unchanged values and failed checks are not reported
+	select {
+	case snapshot := <-changes:
+		t.Fatalf("Expected no change, got %v", snapshot.Env)
+	case <-time.After(30 * time.Millisecond):
+	}
+	repo.set(nil, errors.New("connection refused"))
+	select {
+	case snapshot := <-changes:
+		t.Fatalf("Expected no change after a failed check, got %v", snapshot.Env)
+	case <-time.After(30 * time.Millisecond):
+	}
+
+	next := map[string]string{"A": "1", "B": "3", "C": "4"}
+	repo.set(next, nil)
+
+	select {
+	case snapshot := <-changes:
+		if !maps.Equal(snapshot.Env, next) {
+			t.Fatalf("Expected %v, got %v", next, snapshot.Env)
+		}
+
+		diff := DiffSnapshots(EnvSnapshot{Env: current}, snapshot)
+		if !slices.Equal(diff.Added, []string{"C"}) || !slices.Equal(diff.Updated, []string{"B"}) || len(diff.Removed) != 0 {
+			t.Errorf("Unexpected changes %+v", diff)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the change to be reported")
+	}
+
+	cancel()
+	for range changes {
+	}
+}
+
+func TestDiffSnapshotsSecretsWin(t *testing.T) {
+	before := EnvSnapshot{
+		Env:     map[string]string{"TOKEN": "plain", "OLD": "x"},
+		Secrets: map[string]string{"TOKEN": "secret"},
+	}
+	after := EnvSnapshot{
+		Env:     map[string]string{"TOKEN": "changed"},
+		Secrets: map[string]string{"TOKEN": "secret"},
+	}
+
+	diff := DiffSnapshots(before, after)
+	if len(diff.Added) != 0 || len(diff.Updated) != 0 || !slices.Equal(diff.Removed, []string{"OLD"}) {
+		t.Errorf("Unexpected changes %+v", diff)
+	}
+}
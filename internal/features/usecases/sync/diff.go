@@ -0,0 +1,248 @@
+package sync
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/EnvSync-Cloud/envsync-cli/internal/domain"
+	"github.com/EnvSync-Cloud/envsync-cli/internal/services"
+)
+
+type diffUseCase struct {
+	appService     services.ApplicationService
+	envTypeService services.EnvTypeService
+	// remoteService creates the sync services reading both sides of a remote diff
+	remoteService func(configPath string, cfg domain.SyncConfig, app domain.Application, envType domain.EnvType) services.SyncService
+}
+
+func NewDiffUseCase() DiffUseCase {
+	appService := services.NewAppService()
+	envTypeService := services.NewEnvTypeService()
+	return &diffUseCase{
+		appService:     appService,
+		envTypeService: envTypeService,
+		remoteService:  remoteSyncService,
+	}
+}
+
+func (uc *diffUseCase) Execute(ctx context.Context, opts DiffOptions) ([]DiffResponse, error) {
+	switch {
+	case len(opts.EnvTypes) > 2:
+		return nil, NewValidationError("--env can be given at most twice", "", nil)
+	case len(opts.Apps) > 2:
+		return nil, NewValidationError("--app can be given at most twice", "", nil)
+	case len(opts.EnvTypes) == 1 && len(opts.Apps) == 0:
+		return nil, NewValidationError("pass --env twice to compare two environment types", "", nil)
+	case len(opts.EnvTypes) == 0 && len(opts.Apps) == 0:
+		return uc.diffLocal(opts)
+	default:
+		response, err := uc.diffRemote(opts)
+		if err != nil {
+			return nil, err
+		}
+		return []DiffResponse{response}, nil
+	}
+}
+
+// diffLocal compares every configured env file with its remote environment type
+func (uc *diffUseCase) diffLocal(opts DiffOptions) ([]DiffResponse, error) {
+	targets, err := resolveTargets(uc.envTypeService, SyncOptions{
+		ConfigPath: opts.ConfigPath,
+		Only:       opts.Only,
+		Exclude:    opts.Exclude,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]DiffResponse, 0, len(targets))
+	for _, target := range targets {
+		remote, err := readRemoteEnv(target.service)
+		if err != nil {
+			return nil, err
+		}
+
+		local, err := target.service.ReadLocalEnv()
+		if err != nil {
+			return nil, NewFileSystemError("failed to read local environment variables", err)
+		}
+
+		responses = append(responses, calculateDiff(target.EnvType.Name, target.EnvFile, remote, local, target.filter))
+	}
+
+	return responses, nil
+}
+
+// diffRemote compares two remote environment types, of the same app or of two apps.
+// Whatever is not given on the command line is taken from the project configuration.
+func (uc *diffUseCase) diffRemote(opts DiffOptions) (DiffResponse, error) {
+	cfg, err := services.ReadSyncConfig(opts.ConfigPath)
+	if err != nil {
+		return DiffResponse{}, NewCorruptedError("failed to read configuration file", err)
+	}
+
+	filter := keyFilter(cfg, opts.Only, opts.Exclude)
+	if err := filter.Validate(); err != nil {
+		return DiffResponse{}, NewValidationError("invalid key filter", "", err)
+	}
+
+	fromApp, toApp, err := uc.resolveApps(cfg.AppID, opts.Apps)
+	if err != nil {
+		return DiffResponse{}, err
+	}
+
+	var fromRef string
+	if len(opts.EnvTypes) > 0 {
+		fromRef = opts.EnvTypes[0]
+	} else {
+		// The configured environment type belongs to the configured app,
+		// the apps given with --app are compared by its name
+		if cfg.EnvTypeID == "" {
+			return DiffResponse{}, NewValidationError("no environment type configured, choose one with --env", "", nil)
+		}
+		configured, err := uc.resolveEnvType(domain.Application{ID: cfg.AppID, Name: cfg.AppID}, cfg.EnvTypeID)
+		if err != nil {
+			return DiffResponse{}, err
+		}
+		fromRef = configured.Name
+	}
+
+	fromEnv, err := uc.resolveEnvType(fromApp, fromRef)
+	if err != nil {
+		return DiffResponse{}, err
+	}
+
+	// Environment types are matched by name across apps
+	toRef := fromEnv.Name
+	if len(opts.EnvTypes) == 2 {
+		toRef = opts.EnvTypes[1]
+	}
+
+	toEnv, err := uc.resolveEnvType(toApp, toRef)
+	if err != nil {
+		return DiffResponse{}, err
+	}
+
+	if fromApp.ID == toApp.ID && fromEnv.ID == toEnv.ID {
+		return DiffResponse{}, NewValidationError("both sides of the diff are "+fromEnv.Name+", nothing to compare", "", nil)
+	}
+
+	from, err := readRemoteEnv(uc.remoteService(opts.ConfigPath, cfg, fromApp, fromEnv))
+	if err != nil {
+		return DiffResponse{}, err
+	}
+
+	to, err := readRemoteEnv(uc.remoteService(opts.ConfigPath, cfg, toApp, toEnv))
+	if err != nil {
+		return DiffResponse{}, err
+	}
+
+	fromLabel, toLabel := fromEnv.Name, toEnv.Name
+	if fromApp.ID != toApp.ID {
+		fromLabel = fromApp.Name + "/" + fromLabel
+		toLabel = toApp.Name + "/" + toLabel
+	}
+
+	return calculateDiff(fromLabel, toLabel, from, to, filter), nil
+}
+
+// resolveApps returns the apps on both sides of the diff. A single --app is
+// compared with the configured app.
+func (uc *diffUseCase) resolveApps(configuredAppID string, refs []string) (domain.Application, domain.Application, error) {
+	refs = slices.Clone(refs)
+	switch len(refs) {
+	case 0:
+		refs = []string{configuredAppID, configuredAppID}
+	case 1:
+		refs = []string{configuredAppID, refs[0]}
+	}
+	if refs[0] == "" {
+		return domain.Application{}, domain.Application{}, NewValidationError("no app configured, choose one with --app", "", nil)
+	}
+
+	apps, err := uc.appService.GetAllApps()
+	if err != nil {
+		return domain.Application{}, domain.Application{}, NewServiceError("failed to fetch apps", err)
+	}
+
+	var resolved [2]domain.Application
+	for i, ref := range refs {
+		idx := slices.IndexFunc(apps, func(app domain.Application) bool {
+			return app.ID == ref || strings.EqualFold(app.Name, ref)
+		})
+		if idx == -1 {
+			return domain.Application{}, domain.Application{}, NewNotFoundError("app "+ref+" not found", nil)
+		}
+		resolved[i] = apps[idx]
+	}
+
+	return resolved[0], resolved[1], nil
+}
+
+func (uc *diffUseCase) resolveEnvType(app domain.Application, ref string) (domain.EnvType, error) {
+	envTypes, err := uc.envTypeService.GetEnvTypesByAppID(app.ID)
+	if err != nil {
+		return domain.EnvType{}, NewServiceError("failed to fetch environment types", err)
+	}
+
+	envType, ok := domain.FindEnvType(envTypes, ref)
+	if !ok {
+		return domain.EnvType{}, NewNotFoundError("environment type "+ref+" not found in app "+app.Name, nil)
+	}
+
+	return envType, nil
+}
+
+// remoteSyncService creates a sync service reading the variables of envType in app
+func remoteSyncService(configPath string, cfg domain.SyncConfig, app domain.Application, envType domain.EnvType) services.SyncService {
+	cfg.AppID = app.ID
+	return services.NewSyncServiceForTarget(configPath, cfg, domain.SyncTarget{EnvType: envType})
+}
+
+func readRemoteEnv(syncService services.SyncService) (map[string]string, error) {
+	remoteEnv, err := syncService.ReadRemoteEnv()
+	if err != nil {
+		return nil, NewServiceError("failed to read remote environment variables", err)
+	}
+
+	env := make(map[string]string, len(remoteEnv))
+	for _, v := range remoteEnv {
+		env[v.Key] = v.Value
+	}
+	return env, nil
+}
+
+// calculateDiff reports the changes that turn from into to
+func calculateDiff(fromLabel, toLabel string, from, to map[string]string, filter domain.KeyFilter) DiffResponse {
+	fromVars := make(map[string]domain.EnvironmentVariable, len(from))
+	for k, v := range from {
+		fromVars[k] = domain.EnvironmentVariable{Key: k, Value: v}
+	}
+
+	envSync := domain.NewEnvironmentSync(to, fromVars)
+	envSync.Filter = filter
+	envSync.CalculateDiff()
+
+	response := DiffResponse{
+		From:    fromLabel,
+		To:      toLabel,
+		Added:   envSync.ToAdd,
+		Changed: make([]DiffChange, 0, len(envSync.ToUpdate)),
+		Removed: make([]domain.EnvironmentVariable, 0, len(envSync.ToDelete)),
+	}
+	for _, v := range envSync.ToUpdate {
+		response.Changed = append(response.Changed, DiffChange{Key: v.Key, From: from[v.Key], To: v.Value})
+	}
+	for _, key := range envSync.ToDelete {
+		response.Removed = append(response.Removed, fromVars[key])
+	}
+
+	byKey := func(a, b domain.EnvironmentVariable) int { return cmp.Compare(a.Key, b.Key) }
+	slices.SortFunc(response.Added, byKey)
+	slices.SortFunc(response.Removed, byKey)
+	slices.SortFunc(response.Changed, func(a, b DiffChange) int { return cmp.Compare(a.Key, b.Key) })
+
+	return response
+}
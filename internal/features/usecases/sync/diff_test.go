@@ -0,0 +1,163 @@
+package sync
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/EnvSync-Cloud/envsync-cli/internal/constants"
+	"github.com/EnvSync-Cloud/envsync-cli/internal/domain"
+	"github.com/EnvSync-Cloud/envsync-cli/internal/services"
+)
+
+// fakeAppService holds the apps of the organisation
+type fakeAppService struct {
+	apps []domain.Application
+}
+
+func (s *fakeAppService) CreateApp(app *domain.Application) (domain.Application, error) {
+	return *app, nil
+}
+
+func (s *fakeAppService) GetAppByID(id string) (domain.Application, error) {
+	for _, app := range s.apps {
+		if app.ID == id {
+			return app, nil
+		}
+	}
+	return domain.Application{}, nil
+}
+
+func (s *fakeAppService) GetAllApps() ([]domain.Application, error) {
+	return s.apps, nil
+}
+
+func (s *fakeAppService) DeleteApp(app domain.Application) error {
+	return nil
+}
+
+func TestCalculateDiff(t *testing.T) {
+	from := map[string]string{"A": "1", "B": "2", "C": "3", "LOCAL_X": "x"}
+	to := map[string]string{"B": "2", "C": "4", "D": "5", "LOCAL_Y": "y"}
+
+	got := calculateDiff("dev", "prod", from, to, domain.KeyFilter{Exclude: []string{"LOCAL_*"}})
+
+	expected := DiffResponse{
+		From:    "dev",
+		To:      "prod",
+		Added:   []domain.EnvironmentVariable{{Key: "D", Value: "5"}},
+		Changed: []DiffChange{{Key: "C", From: "3", To: "4"}},
+		Removed: []domain.EnvironmentVariable{{Key: "A", Value: "1"}},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, got)
+	}
+
+	if same := calculateDiff("dev", "prod", from, from, domain.KeyFilter{}); same.HasChanges() {
+		t.Errorf("Expected no changes between identical sides, got %+v", same)
+	}
+}
+
+func TestDiffRemote(t *testing.T) {
+	// The project is configured for the core app, the other apps have
+	// environment types of the same names with other IDs
+	configPath := filepath.Join(t.TempDir(), constants.DefaultProjectConfig)
+	if err := os.WriteFile(configPath, []byte("app_id = \"core\"\nenv_type_id = \"core-dev\"\n"), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	remote := map[string]map[string]string{
+		"core-dev":      {"A": "1", "B": "core"},
+		"core-prod":     {"A": "1", "B": "prod"},
+		"billing-dev":   {"A": "1", "B": "billing"},
+		"payments-dev":  {"A": "2"},
+		"payments-prod": {"A": "1", "B": "prod", "C": "3"},
+	}
+
+	uc := &diffUseCase{
+		appService: &fakeAppService{apps: []domain.Application{
+			{ID: "core", Name: "core"},
+			{ID: "billing", Name: "billing"},
+			{ID: "payments", Name: "payments"},
+		}},
+		envTypeService: &fakeEnvTypeService{envTypes: []domain.EnvType{
+			{ID: "core-dev", AppID: "core", Name: "dev"},
+			{ID: "core-prod", AppID: "core", Name: "prod"},
+			{ID: "billing-dev", AppID: "billing", Name: "dev"},
+			{ID: "payments-dev", AppID: "payments", Name: "dev"},
+			{ID: "payments-prod", AppID: "payments", Name: "prod"},
+		}},
+		remoteService: func(configPath string, cfg domain.SyncConfig, app domain.Application, envType domain.EnvType) services.SyncService {
+			cfg.AppID, cfg.EnvTypeID = app.ID, envType.ID
+			return services.NewSyncServiceFromRepository(&fakeEnvRepository{env: remote[envType.ID]}, cfg)
+		},
+	}
+
+	tests := []struct {
+		name     string
+		opts     DiffOptions
+		expected DiffResponse
+	}{
+		{
+			name: "two apps without --env",
+			opts: DiffOptions{Apps: []string{"billing", "payments"}},
+			expected: DiffResponse{
+				From:    "billing/dev",
+				To:      "payments/dev",
+				Added:   []domain.EnvironmentVariable{},
+				Changed: []DiffChange{{Key: "A", From: "1", To: "2"}},
+				Removed: []domain.EnvironmentVariable{{Key: "B", Value: "billing"}},
+			},
+		},
+		{
+			name: "one app and one env",
+			opts: DiffOptions{Apps: []string{"payments"}, EnvTypes: []string{"prod"}},
+			expected: DiffResponse{
+				From:    "core/prod",
+				To:      "payments/prod",
+				Added:   []domain.EnvironmentVariable{{Key: "C", Value: "3"}},
+				Changed: []DiffChange{},
+				Removed: []domain.EnvironmentVariable{},
+			},
+		},
+		{
+			name: "two envs of the configured app",
+			opts: DiffOptions{EnvTypes: []string{"dev", "core-prod"}},
+			expected: DiffResponse{
+				From:    "dev",
+				To:      "prod",
+				Added:   []domain.EnvironmentVariable{},
+				Changed: []DiffChange{{Key: "B", From: "core", To: "prod"}},
+				Removed: []domain.EnvironmentVariable{},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.ConfigPath = configPath
+			responses, err := uc.Execute(context.Background(), tt.opts)
+			if err != nil {
+				t.Fatalf("Execute returned error: %v", err)
+			}
+			if len(responses) != 1 || !reflect.DeepEqual(responses[0], tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, responses)
+			}
+		})
+	}
+
+	invalid := []DiffOptions{
+		{EnvTypes: []string{"dev"}},
+		{EnvTypes: []string{"dev", "dev"}},
+		{Apps: []string{"unknown"}},
+		{Apps: []string{"billing"}, EnvTypes: []string{"prod"}},
+	}
+	for _, opts := range invalid {
+		opts.ConfigPath = configPath
+		if _, err := uc.Execute(context.Background(), opts); err == nil {
+			t.Errorf("Expected an error for %+v", opts)
+		}
+	}
+}
@@ -23,11 +23,16 @@ var (
 	ErrSyncAlreadyExists  = errors.New("sync already exists")
 	ErrSyncLocked         = errors.New("sync is locked and cannot be modified")
 	ErrSyncBackupFailed   = errors.New("failed to create sync backup")
+	ErrSyncNotConfirmed   = errors.New("confirmation required")
 
 	// External service errors
 	ErrSyncServiceUnavailable = errors.New("sync service is currently unavailable")
 	ErrSyncValidationFailed   = errors.New("sync validation failed")
 	ErrSyncFailed             = errors.New("failed to sync")
+
+	// Conflict errors
+	ErrSyncConflict        = errors.New("local and remote changes conflict")
+	ErrInvalidSyncStrategy = errors.New("invalid conflict resolution strategy")
 )
 
 // Error types for structured error handling
@@ -64,6 +69,7 @@ const (
 	SyncErrorCodeNotFound     = "SYNC_NOT_FOUND"
 	SyncErrorCodeCorrupted    = "SYNC_CORRUPTED"
 	SyncErrorCodeServiceError = "SERVICE_ERROR"
+	SyncErrorCodeConflict     = "SYNC_CONFLICT"
 )
 
 // Helper functions to create structured errors
@@ -116,6 +122,14 @@ func NewServiceError(message string, cause error) *SyncError {
 	}
 }
 
+func NewConflictError(message string, cause error) *SyncError {
+	return &SyncError{
+		Code:    SyncErrorCodeConflict,
+		Message: message,
+		Cause:   cause,
+	}
+}
+
 // Validation severity levels
 const (
 	ValidationSeverityError   = "error"
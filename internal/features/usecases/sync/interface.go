@@ -7,17 +7,124 @@ import (
 )
 
 type PushUseCase interface {
-	Execute(context.Context, string) (SyncResponse, error)
+	Execute(context.Context, SyncOptions) ([]SyncResponse, error)
 }
 
 type PullUseCase interface {
-	Execute(context.Context, string) (SyncResponse, error)
+	Execute(context.Context, SyncOptions) ([]SyncResponse, error)
 }
 
+type ListBackupsUseCase interface {
+	Execute(context.Context, RestoreOptions) ([]domain.EnvBackup, error)
+}
+
+type RestoreUseCase interface {
+	Execute(context.Context, RestoreOptions) (RestoreResponse, error)
+}
+
+type DiffUseCase interface {
+	Execute(context.Context, DiffOptions) ([]DiffResponse, error)
+}
+
+// SyncOptions controls how a pull or push is executed
+type SyncOptions struct {
+	ConfigPath string
+	// DryRun calculates the changes without writing anything
+	DryRun bool
+	// Strategy resolves conflicts without prompting: ours, theirs or fail.
+	// When empty, conflicts are resolved interactively if Interactive is set
+	// and left untouched otherwise.
+	Strategy string
+	// Interactive allows prompting the user in the terminal
+	Interactive bool
+	// Only and Exclude are key globs narrowing the configured key filter
+	Only    []string
+	Exclude []string
+	// ConfirmProtected allows pushing to a protected environment type without prompting
+	ConfirmProtected bool
+	// ConfirmDeletes allows a push to delete more variables than the configured threshold
+	ConfirmDeletes bool
+}
+
+// Conflict resolution strategies
+const (
+	StrategyOurs   = "ours"
+	StrategyTheirs = "theirs"
+	StrategyFail   = "fail"
+)
+
+// SyncResponse is the outcome of syncing a single env file
 type SyncResponse struct {
+	EnvFile   string                       `json:"env_file"`
+	EnvType   string                       `json:"env_type"`
 	Added     []domain.EnvironmentVariable `json:"added"`
 	Updated   []domain.EnvironmentVariable `json:"updated"`
 	Deleted   []domain.EnvironmentVariable `json:"deleted"`
-	Conflicts []domain.EnvironmentVariable `json:"conflicts"`
+	Conflicts []domain.EnvironmentConflict `json:"conflicts"`
+	Resolved  []string                     `json:"resolved,omitempty"`
 	Warnings  []string                     `json:"warnings,omitempty"`
+	DryRun    bool                         `json:"dry_run"`
+}
+
+// HasChanges returns true if the sync applies (or would apply) any change
+func (r SyncResponse) HasChanges() bool {
+	return len(r.Added) > 0 || len(r.Updated) > 0 || len(r.Deleted) > 0
+}
+
+// RestoreOptions controls which backup is listed or restored
+type RestoreOptions struct {
+	ConfigPath string
+	// EnvFile limits the command to one of the configured env files
+	EnvFile string
+	// At selects a backup by timestamp or timestamp prefix, the newest one when empty
+	At string
+	// DryRun shows the changes without restoring
+	DryRun bool
+	// Confirmed skips the confirmation prompt
+	Confirmed bool
+	// Interactive allows prompting the user in the terminal
+	Interactive bool
+}
+
+// RestoreResponse describes the changes a restore makes to the env file
+type RestoreResponse struct {
+	Backup  domain.EnvBackup             `json:"backup"`
+	Added   []domain.EnvironmentVariable `json:"added"`
+	Updated []domain.EnvironmentVariable `json:"updated"`
+	Deleted []domain.EnvironmentVariable `json:"deleted"`
+	DryRun  bool                         `json:"dry_run"`
+}
+
+// DiffOptions selects what envsync diff compares. Without environment types or
+// apps the configured env files are compared with the remote.
+type DiffOptions struct {
+	ConfigPath string
+	// EnvTypes are the environment types to compare, by name or ID
+	EnvTypes []string
+	// Apps are the apps to compare, by name or ID. A single app is compared with the configured one.
+	Apps []string
+	// Only limits the diff to keys matching these globs
+	Only []string
+	// Exclude skips keys matching these globs
+	Exclude []string
+}
+
+// DiffResponse holds the differences that turn From into To
+type DiffResponse struct {
+	From    string                       `json:"from"`
+	To      string                       `json:"to"`
+	Added   []domain.EnvironmentVariable `json:"added"`
+	Changed []DiffChange                 `json:"changed"`
+	Removed []domain.EnvironmentVariable `json:"removed"`
+}
+
+func (r DiffResponse) HasChanges() bool {
+	return len(r.Added) > 0 || len(r.Changed) > 0 || len(r.Removed) > 0
+}
+
+// DiffChange is a key whose value differs between both sides of a diff
+type DiffChange struct {
+	Key  string `json:"key"`
+	From string `json:"from"`
+	To   string `json:"to"`
 }
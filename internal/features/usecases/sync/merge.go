@@ -0,0 +1,148 @@
+package sync
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/EnvSync-Cloud/envsync-cli/internal/domain"
+	"github.com/EnvSync-Cloud/envsync-cli/internal/presentation/tui/factory"
+	"github.com/EnvSync-Cloud/envsync-cli/internal/services"
+)
+
+// readSnapshot returns the merge base recorded by the last sync. A corrupted
+// snapshot is ignored with a warning, both sides are then merged without a
+// base and every differing key becomes a conflict.
+func readSnapshot(syncService services.SyncService) (domain.SyncSnapshot, string, error) {
+	snapshot, err := syncService.ReadSnapshot()
+	if errors.Is(err, services.ErrCorruptedSnapshot) {
+		return snapshot, fmt.Sprintf("Ignoring the last sync snapshot (%v), merging without a base", err), nil
+	}
+	if err != nil {
+		return snapshot, "", NewCorruptedError("failed to read last sync snapshot", err)
+	}
+	return snapshot, "", nil
+}
+
+// conflictWarnings describes each conflict without exposing its values
+func conflictWarnings(conflicts []domain.EnvironmentConflict) []string {
+	warnings := make([]string, 0, len(conflicts))
+	for _, c := range conflicts {
+		switch {
+		case c.LocalDeleted:
+			warnings = append(warnings, "Conflict for key '"+c.Key+"': deleted locally but changed remotely")
+		case c.RemoteDeleted:
+			warnings = append(warnings, "Conflict for key '"+c.Key+"': changed locally but deleted remotely")
+		default:
+			warnings = append(warnings, "Conflict for key '"+c.Key+"': changed both locally and remotely")
+		}
+	}
+	return warnings
+}
+
+func changeCount(cs domain.ChangeSet) int {
+	return len(cs.Added) + len(cs.Updated) + len(cs.Deleted)
+}
+
+// resolveConflicts picks a resolution for each conflict according to the
+// strategy, falling back to the interactive resolver when allowed. Conflicts
+// without a resolution are left untouched.
+func resolveConflicts(tui *factory.SyncFactory, conflicts []domain.EnvironmentConflict, opts SyncOptions) ([]domain.ConflictResolution, error) {
+	if len(conflicts) == 0 {
+		return nil, nil
+	}
+
+	var resolutions []domain.ConflictResolution
+	switch opts.Strategy {
+	case StrategyOurs:
+		for _, c := range conflicts {
+			resolutions = append(resolutions, c.KeepLocal())
+		}
+	case StrategyTheirs:
+		for _, c := range conflicts {
+			resolutions = append(resolutions, c.TakeRemote())
+		}
+	case StrategyFail:
+		keys := make([]string, len(conflicts))
+		for i, c := range conflicts {
+			keys[i] = c.Key
+		}
+		return nil, NewConflictError(
+			fmt.Sprintf("%d conflicting variable(s): %s", len(conflicts), strings.Join(keys, ", ")),
+			ErrSyncConflict,
+		)
+	case "":
+		if !opts.Interactive || opts.DryRun {
+			return nil, nil
+		}
+		return tui.ResolveConflictsTUI(conflicts)
+	default:
+		return nil, NewValidationError("unknown conflict resolution strategy '"+opts.Strategy+"'", "", ErrInvalidSyncStrategy)
+	}
+
+	return resolutions, nil
+}
+
+// applyResolutions adds the resolved values that differ from the target side
+// to the changes applied to it
+func applyResolutions(changes domain.ChangeSet, target map[string]string, resolutions []domain.ConflictResolution) domain.ChangeSet {
+	result := domain.ChangeSet{
+		Added:   slices.Clone(changes.Added),
+		Updated: slices.Clone(changes.Updated),
+		Deleted: slices.Clone(changes.Deleted),
+	}
+
+	for _, r := range resolutions {
+		current, exists := target[r.Key]
+		switch {
+		case r.Delete && exists:
+			result.Deleted = append(result.Deleted, domain.EnvironmentVariable{Key: r.Key, Value: current})
+		case r.Delete:
+		case !exists:
+			result.Added = append(result.Added, domain.EnvironmentVariable{Key: r.Key, Value: r.Value})
+		case current != r.Value:
+			result.Updated = append(result.Updated, domain.EnvironmentVariable{Key: r.Key, Value: r.Value})
+		}
+	}
+
+	return result
+}
+
+// rebaseResolved records the source side as the merge base for resolved keys,
+// so a resolution that differs from the source shows up as a plain change on
+// the next sync in the other direction instead of as the same conflict again
+func rebaseResolved(base, source map[string]string, resolutions []domain.ConflictResolution) map[string]string {
+	for _, r := range resolutions {
+		if value, ok := source[r.Key]; ok {
+			base[r.Key] = value
+		} else {
+			delete(base, r.Key)
+		}
+	}
+	return base
+}
+
+// unresolvedConflicts returns the conflicts that were not resolved
+func unresolvedConflicts(conflicts []domain.EnvironmentConflict, resolutions []domain.ConflictResolution) []domain.EnvironmentConflict {
+	resolved := make(map[string]bool, len(resolutions))
+	for _, r := range resolutions {
+		resolved[r.Key] = true
+	}
+
+	var remaining []domain.EnvironmentConflict
+	for _, c := range conflicts {
+		if !resolved[c.Key] {
+			remaining = append(remaining, c)
+		}
+	}
+	return remaining
+}
+
+func resolvedKeys(resolutions []domain.ConflictResolution) []string {
+	keys := make([]string, len(resolutions))
+	for i, r := range resolutions {
+		keys[i] = r.Key
+	}
+	return keys
+}
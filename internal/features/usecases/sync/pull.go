@@ -6,29 +6,52 @@ import (
 	"os"
 
 	"github.com/EnvSync-Cloud/envsync-cli/internal/domain"
+	"github.com/EnvSync-Cloud/envsync-cli/internal/presentation/tui/factory"
 	"github.com/EnvSync-Cloud/envsync-cli/internal/services"
-	"github.com/joho/godotenv"
 )
 
 type pullUseCase struct {
-	syncService services.SyncService
+	envTypeService services.EnvTypeService
+	tui            *factory.SyncFactory
 }
 
 func NewPullUseCase() PullUseCase {
-	service := services.NewSyncService()
+	envTypeService := services.NewEnvTypeService()
+	tui := factory.NewSyncFactory()
 	return &pullUseCase{
-		syncService: service,
+		envTypeService: envTypeService,
+		tui:            tui,
 	}
 }
 
-func (uc *pullUseCase) Execute(ctx context.Context, configPath string) (SyncResponse, error) {
+func (uc *pullUseCase) Execute(ctx context.Context, opts SyncOptions) ([]SyncResponse, error) {
 	// Check if the configuration file exists
-	if err := uc.checkConfigFileExists(configPath); err != nil {
-		return SyncResponse{}, NewFileSystemError("configuration file check failed", err)
+	if err := uc.checkConfigFileExists(opts.ConfigPath); err != nil {
+		return nil, NewFileSystemError("configuration file check failed", err)
 	}
 
+	targets, err := resolveTargets(uc.envTypeService, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]SyncResponse, 0, len(targets))
+	for _, target := range targets {
+		diff, err := uc.pullTarget(target, opts)
+		if err != nil {
+			return responses, err
+		}
+		responses = append(responses, diff)
+	}
+
+	return responses, nil
+}
+
+func (uc *pullUseCase) pullTarget(target syncTarget, opts SyncOptions) (SyncResponse, error) {
+	syncService := target.service
+
 	// Read remote remote environment variables
-	remoteEnv, err := uc.syncService.ReadRemoteEnv()
+	remoteEnv, err := syncService.ReadRemoteEnv()
 	if err != nil {
 		return SyncResponse{}, NewServiceError("failed to read remote environment variables", err)
 	}
@@ -40,24 +63,53 @@ func (uc *pullUseCase) Execute(ctx context.Context, configPath string) (SyncResp
 	}
 
 	// Read local environment variables from the specified config file
-	localEnv, err := uc.syncService.ReadLocalEnv()
+	localEnv, err := syncService.ReadLocalEnv()
 	if err != nil {
 		return SyncResponse{}, NewFileSystemError("failed to read local environment variables", err)
 	}
 
-	// Calculate the differences between remote and local environment variables
-	diff, err := uc.calculateEnvDiff(remoteEnvMap, localEnv)
+	// Read the state of the last sync to use as merge base
+	snapshot, snapshotWarning, err := readSnapshot(syncService)
 	if err != nil {
-		return SyncResponse{}, NewValidationError("failed to calculate environment differences", "", err)
+		return SyncResponse{}, err
 	}
 
-	if len(diff.Added) > 0 || len(diff.Updated) > 0 || len(diff.Deleted) > 0 {
-		err := uc.writeToLocalEnv(remoteEnvMap)
-		if err != nil {
+	// Calculate the changes made remotely since the last sync
+	merge := uc.calculateEnvDiff(remoteEnvMap, localEnv, snapshot.Variables, target.filter)
+
+	// Resolve conflicting keys before anything is written
+	resolutions, err := resolveConflicts(uc.tui, merge.Conflicts, opts)
+	if err != nil {
+		return SyncResponse{}, err
+	}
+
+	changes := applyResolutions(merge.RemoteChanges, localEnv, resolutions)
+	diff := uc.buildResponse(merge, changes, resolutions)
+	diff.EnvFile = target.EnvFile
+	diff.EnvType = target.EnvType.Name
+	if snapshotWarning != "" {
+		diff.Warnings = append(diff.Warnings, snapshotWarning)
+	}
+
+	if opts.DryRun {
+		diff.DryRun = true
+		return diff, nil
+	}
+
+	updatedLocal := localEnv
+	if !changes.IsEmpty() {
+		updatedLocal = changes.Apply(localEnv)
+		if err := syncService.WriteLocalEnv(updatedLocal); err != nil {
 			return SyncResponse{}, NewFileSystemError("failed to write updated environment variables to local file", err)
 		}
 	}
 
+	base := domain.ReconcileBase(snapshot.Variables, updatedLocal, remoteEnvMap)
+	base = rebaseResolved(base, remoteEnvMap, resolutions)
+	if err := syncService.WriteSnapshot(base); err != nil {
+		return SyncResponse{}, NewFileSystemError("failed to write sync snapshot", err)
+	}
+
 	return diff, nil
 }
 
@@ -70,45 +122,33 @@ func (uc *pullUseCase) checkConfigFileExists(configPath string) error {
 	return nil
 }
 
-func (uc *pullUseCase) calculateEnvDiff(remoteEnv, localEnv map[string]string) (SyncResponse, error) {
-	added := make([]domain.EnvironmentVariable, 0)
-	updated := make([]domain.EnvironmentVariable, 0)
-	deleted := make([]domain.EnvironmentVariable, 0)
-	conflicts := make([]domain.EnvironmentVariable, 0)
-	warnings := make([]string, 0)
-
-	// Track keys in local for deletion detection
-	localKeys := make(map[string]struct{}, len(localEnv))
-	for k := range localEnv {
-		localKeys[k] = struct{}{}
-	}
-
-	// Check for added and updated/conflicted keys (from remote to local)
-	for k, remoteVal := range remoteEnv {
-		if localVal, ok := localEnv[k]; !ok {
-			added = append(added, domain.EnvironmentVariable{Key: k, Value: remoteVal})
-		} else if localVal != remoteVal {
-			updated = append(updated, domain.EnvironmentVariable{Key: k, Value: remoteVal})
-			conflicts = append(conflicts, domain.EnvironmentVariable{Key: k, Value: remoteVal})
-			warnings = append(warnings, "Conflict for key '"+k+"': local='"+localVal+"' remote='"+remoteVal+"'")
-		}
-		delete(localKeys, k)
+func (uc *pullUseCase) calculateEnvDiff(remoteEnv, localEnv, baseEnv map[string]string, filter domain.KeyFilter) domain.MergeResult {
+	remoteVars := make(map[string]domain.EnvironmentVariable, len(remoteEnv))
+	for k, v := range remoteEnv {
+		remoteVars[k] = domain.EnvironmentVariable{Key: k, Value: v}
 	}
 
-	// Remaining keys in localKeys are deleted in remote (should be deleted from local)
-	for k := range localKeys {
-		deleted = append(deleted, domain.EnvironmentVariable{Key: k, Value: localEnv[k]})
+	envSync := domain.NewEnvironmentSync(localEnv, remoteVars)
+	envSync.Base = baseEnv
+	envSync.Filter = filter
+	return envSync.Merge()
+}
+
+func (uc *pullUseCase) buildResponse(merge domain.MergeResult, changes domain.ChangeSet, resolutions []domain.ConflictResolution) SyncResponse {
+	conflicts := unresolvedConflicts(merge.Conflicts, resolutions)
+
+	warnings := conflictWarnings(conflicts)
+	if pending := changeCount(merge.LocalChanges); pending > 0 {
+		warnings = append(warnings, fmt.Sprintf("%d local change(s) are not on the remote yet, run 'envsync push' to publish them", pending))
 	}
 
+	// Pull applies the remote side of the merge to the local file
 	return SyncResponse{
-		Added:     added,
-		Updated:   updated,
-		Deleted:   deleted,
+		Added:     changes.Added,
+		Updated:   changes.Updated,
+		Deleted:   changes.Deleted,
 		Conflicts: conflicts,
+		Resolved:  resolvedKeys(resolutions),
 		Warnings:  warnings,
-	}, nil
-}
-
-func (uc *pullUseCase) writeToLocalEnv(env map[string]string) error {
-	return godotenv.Write(env, ".env")
+	}
 }
@@ -6,28 +6,52 @@ import (
 	"os"
 
 	"github.com/EnvSync-Cloud/envsync-cli/internal/domain"
+	"github.com/EnvSync-Cloud/envsync-cli/internal/presentation/tui/factory"
 	"github.com/EnvSync-Cloud/envsync-cli/internal/services"
 )
 
 type pushUseCase struct {
-	syncService services.SyncService
+	envTypeService services.EnvTypeService
+	tui            *factory.SyncFactory
 }
 
 func NewPushUseCase() PushUseCase {
-	service := services.NewSyncService()
+	envTypeService := services.NewEnvTypeService()
+	tui := factory.NewSyncFactory()
 	return &pushUseCase{
-		syncService: service,
+		envTypeService: envTypeService,
+		tui:            tui,
 	}
 }
 
-func (uc *pushUseCase) Execute(ctx context.Context, configPath string) (SyncResponse, error) {
+func (uc *pushUseCase) Execute(ctx context.Context, opts SyncOptions) ([]SyncResponse, error) {
 	// Check if the configuration file exists
-	if err := uc.checkConfigFileExists(configPath); err != nil {
-		return SyncResponse{}, NewFileSystemError("configuration file check failed", err)
+	if err := uc.checkConfigFileExists(opts.ConfigPath); err != nil {
+		return nil, NewFileSystemError("configuration file check failed", err)
 	}
 
+	targets, err := resolveTargets(uc.envTypeService, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]SyncResponse, 0, len(targets))
+	for _, target := range targets {
+		diff, err := uc.pushTarget(target, opts)
+		if err != nil {
+			return responses, err
+		}
+		responses = append(responses, diff)
+	}
+
+	return responses, nil
+}
+
+func (uc *pushUseCase) pushTarget(target syncTarget, opts SyncOptions) (SyncResponse, error) {
+	syncService := target.service
+
 	// Read remote environment variables
-	remoteEnv, err := uc.syncService.ReadRemoteEnv()
+	remoteEnv, err := syncService.ReadRemoteEnv()
 	if err != nil {
 		return SyncResponse{}, NewServiceError("failed to read remote environment variables", err)
 	}
@@ -39,18 +63,45 @@ func (uc *pushUseCase) Execute(ctx context.Context, configPath string) (SyncResp
 	}
 
 	// Read local environment variables from the specified config file
-	localEnv, err := uc.syncService.ReadLocalEnv()
+	localEnv, err := syncService.ReadLocalEnv()
 	if err != nil {
 		return SyncResponse{}, NewFileSystemError("failed to read local environment variables", err)
 	}
 
-	// Calculate the differences between remote and local environment variables
-	diff, err := uc.calculateEnvDiff(remoteEnvMap, localEnv)
+	// Read the state of the last sync to use as merge base
+	snapshot, snapshotWarning, err := readSnapshot(syncService)
+	if err != nil {
+		return SyncResponse{}, err
+	}
+
+	// Calculate the changes made locally since the last sync
+	merge := uc.calculateEnvDiff(remoteEnvMap, localEnv, snapshot.Variables, target.filter)
+
+	// Resolve conflicting keys before anything is written
+	resolutions, err := resolveConflicts(uc.tui, merge.Conflicts, opts)
 	if err != nil {
-		return SyncResponse{}, NewValidationError("failed to calculate environment differences", "", err)
+		return SyncResponse{}, err
+	}
+
+	changes := applyResolutions(merge.LocalChanges, remoteEnvMap, resolutions)
+	diff := uc.buildResponse(merge, changes, resolutions)
+	diff.EnvFile = target.EnvFile
+	diff.EnvType = target.EnvType.Name
+	if snapshotWarning != "" {
+		diff.Warnings = append(diff.Warnings, snapshotWarning)
 	}
 
-	if len(diff.Added) > 0 || len(diff.Updated) > 0 || len(diff.Deleted) > 0 {
+	if opts.DryRun {
+		diff.DryRun = true
+		return diff, nil
+	}
+
+	updatedRemote := remoteEnvMap
+	if !changes.IsEmpty() {
+		if err := uc.confirmPush(target, changes, opts); err != nil {
+			return SyncResponse{}, err
+		}
+
 		envSync := &domain.EnvironmentSync{
 			ToAdd:    diff.Added,
 			ToUpdate: diff.Updated,
@@ -59,14 +110,59 @@ func (uc *pushUseCase) Execute(ctx context.Context, configPath string) (SyncResp
 		for _, v := range diff.Deleted {
 			envSync.ToDelete = append(envSync.ToDelete, v.Key)
 		}
-		if err := uc.syncService.WriteRemoteEnv(envSync); err != nil {
+		if err := syncService.WriteRemoteEnv(envSync); err != nil {
 			return SyncResponse{}, NewServiceError("failed to write remote environment variables", err)
 		}
+		updatedRemote = changes.Apply(remoteEnvMap)
+	}
+
+	base := domain.ReconcileBase(snapshot.Variables, localEnv, updatedRemote)
+	base = rebaseResolved(base, localEnv, resolutions)
+	if err := syncService.WriteSnapshot(base); err != nil {
+		return SyncResponse{}, NewFileSystemError("failed to write sync snapshot", err)
 	}
 
 	return diff, nil
 }
 
+// confirmPush guards protected environment types and bulk deletions. Without
+// the matching flag, the user has to confirm interactively.
+func (uc *pushUseCase) confirmPush(target syncTarget, changes domain.ChangeSet, opts SyncOptions) error {
+	envName := target.EnvType.Name
+
+	if target.EnvType.IsProtected && !opts.ConfirmProtected {
+		if !opts.Interactive {
+			return NewPermissionError(
+				fmt.Sprintf("environment type %s is protected, pass --confirm-protected to push to it", envName),
+				ErrSyncNotConfirmed,
+			)
+		}
+
+		summary := fmt.Sprintf("This push adds %d, updates %d and deletes %d variable(s) in %s.",
+			len(changes.Added), len(changes.Updated), len(changes.Deleted), envName)
+		if err := uc.tui.ConfirmProtectedPushTUI(envName, summary); err != nil {
+			return err
+		}
+	}
+
+	deletes := len(changes.Deleted)
+	if target.deleteThreshold >= 0 && deletes > target.deleteThreshold && !opts.ConfirmDeletes {
+		if !opts.Interactive {
+			return NewPermissionError(
+				fmt.Sprintf("push would delete %d variables from %s, more than the threshold of %d, pass --confirm-deletes to allow it",
+					deletes, envName, target.deleteThreshold),
+				ErrSyncNotConfirmed,
+			)
+		}
+
+		if err := uc.tui.ConfirmDeletesTUI(envName, deletes); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (uc *pushUseCase) checkConfigFileExists(configPath string) error {
 	// Check if the configuration file exists at the specified path
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
@@ -76,43 +172,33 @@ func (uc *pushUseCase) checkConfigFileExists(configPath string) error {
 	return nil
 }
 
-func (uc *pushUseCase) calculateEnvDiff(remoteEnv, localEnv map[string]string) (SyncResponse, error) {
-	var (
-		added     []domain.EnvironmentVariable
-		updated   []domain.EnvironmentVariable
-		deleted   []domain.EnvironmentVariable
-		conflicts []domain.EnvironmentVariable
-		warnings  []string
-	)
-
-	// Track keys in remote for deletion detection
-	remoteKeys := make(map[string]struct{}, len(remoteEnv))
-	for k := range remoteEnv {
-		remoteKeys[k] = struct{}{}
-	}
-
-	// Check for added, updated, and conflicts
-	for k, localVal := range localEnv {
-		if remoteVal, ok := remoteEnv[k]; !ok {
-			added = append(added, domain.EnvironmentVariable{Key: k, Value: localVal})
-		} else if remoteVal != localVal {
-			updated = append(updated, domain.EnvironmentVariable{Key: k, Value: localVal})
-			conflicts = append(conflicts, domain.EnvironmentVariable{Key: k, Value: localVal})
-			warnings = append(warnings, "Conflict for key '"+k+"': remote='"+remoteVal+"' local='"+localVal+"'")
-		}
-		delete(remoteKeys, k)
+func (uc *pushUseCase) calculateEnvDiff(remoteEnv, localEnv, baseEnv map[string]string, filter domain.KeyFilter) domain.MergeResult {
+	remoteVars := make(map[string]domain.EnvironmentVariable, len(remoteEnv))
+	for k, v := range remoteEnv {
+		remoteVars[k] = domain.EnvironmentVariable{Key: k, Value: v}
 	}
 
-	// Remaining keys in remoteKeys are deleted in local
-	for k := range remoteKeys {
-		deleted = append(deleted, domain.EnvironmentVariable{Key: k, Value: remoteEnv[k]})
+	envSync := domain.NewEnvironmentSync(localEnv, remoteVars)
+	envSync.Base = baseEnv
+	envSync.Filter = filter
+	return envSync.Merge()
+}
+
+func (uc *pushUseCase) buildResponse(merge domain.MergeResult, changes domain.ChangeSet, resolutions []domain.ConflictResolution) SyncResponse {
+	conflicts := unresolvedConflicts(merge.Conflicts, resolutions)
+
+	warnings := conflictWarnings(conflicts)
+	if pending := changeCount(merge.RemoteChanges); pending > 0 {
+		warnings = append(warnings, fmt.Sprintf("%d remote change(s) are not in the local file yet, run 'envsync pull' to fetch them", pending))
 	}
 
+	// Push applies the local side of the merge to the remote
 	return SyncResponse{
-		Added:     added,
-		Updated:   updated,
-		Deleted:   deleted,
+		Added:     changes.Added,
+		Updated:   changes.Updated,
+		Deleted:   changes.Deleted,
 		Conflicts: conflicts,
+		Resolved:  resolvedKeys(resolutions),
 		Warnings:  warnings,
-	}, nil
+	}
 }
@@ -0,0 +1,207 @@
+package sync
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/EnvSync-Cloud/envsync-cli/internal/constants"
+	"github.com/EnvSync-Cloud/envsync-cli/internal/domain"
+	"github.com/EnvSync-Cloud/envsync-cli/internal/services"
+)
+
+func deletions(keys ...string) domain.ChangeSet {
+	var changes domain.ChangeSet
+	for _, key := range keys {
+		changes.Deleted = append(changes.Deleted, domain.EnvironmentVariable{Key: key})
+	}
+	return changes
+}
+
+func TestConfirmPush(t *testing.T) {
+	dev := domain.EnvType{Name: "dev"}
+	prod := domain.EnvType{Name: "prod", IsProtected: true}
+
+	tests := []struct {
+		name      string
+		envType   domain.EnvType
+		threshold int
+		changes   domain.ChangeSet
+		opts      SyncOptions
+		refused   bool
+	}{
+		{
+			name:      "deletes within the threshold",
+			envType:   dev,
+			threshold: 2,
+			changes:   deletions("A", "B"),
+		},
+		{
+			name:      "deletes over the threshold",
+			envType:   dev,
+			threshold: 2,
+			changes:   deletions("A", "B", "C"),
+			refused:   true,
+		},
+		{
+			name:      "deletes over the threshold with ConfirmDeletes",
+			envType:   dev,
+			threshold: 2,
+			changes:   deletions("A", "B", "C"),
+			opts:      SyncOptions{ConfirmDeletes: true},
+		},
+		{
+			name:      "zero threshold refuses any delete",
+			envType:   dev,
+			threshold: 0,
+			changes:   deletions("A"),
+			refused:   true,
+		},
+		{
+			name:      "zero threshold allows other changes",
+			envType:   dev,
+			threshold: 0,
+			changes:   domain.ChangeSet{Added: []domain.EnvironmentVariable{{Key: "A", Value: "1"}}},
+		},
+		{
+			name:      "negative threshold disables the check",
+			envType:   dev,
+			threshold: -1,
+			changes:   deletions("A", "B", "C"),
+		},
+		{
+			name:      "protected env type",
+			envType:   prod,
+			threshold: -1,
+			changes:   domain.ChangeSet{Added: []domain.EnvironmentVariable{{Key: "A", Value: "1"}}},
+			refused:   true,
+		},
+		{
+			name:      "protected env type with ConfirmProtected",
+			envType:   prod,
+			threshold: -1,
+			changes:   domain.ChangeSet{Added: []domain.EnvironmentVariable{{Key: "A", Value: "1"}}},
+			opts:      SyncOptions{ConfirmProtected: true},
+		},
+		{
+			name:      "protected env type still checks the threshold",
+			envType:   prod,
+			threshold: 0,
+			changes:   deletions("A"),
+			opts:      SyncOptions{ConfirmProtected: true},
+			refused:   true,
+		},
+	}
+
+	uc := &pushUseCase{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := syncTarget{
+				SyncTarget:      domain.SyncTarget{EnvType: tt.envType},
+				deleteThreshold: tt.threshold,
+			}
+
+			err := uc.confirmPush(target, tt.changes, tt.opts)
+			if tt.refused {
+				if !errors.Is(err, ErrSyncNotConfirmed) {
+					t.Errorf("Expected ErrSyncNotConfirmed, got %v", err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected the push to be allowed, got %v", err)
+			}
+		})
+	}
+}
+
+func TestPushRefusedWritesNothing(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	repo := &fakeEnvRepository{env: map[string]string{"A": "1", "B": "2"}}
+	syncService := services.NewSyncServiceFromRepository(repo, domain.SyncConfig{AppID: "app", EnvTypeID: "dev"})
+
+	// B was deleted locally since the last sync
+	if err := syncService.WriteSnapshot(map[string]string{"A": "1", "B": "2"}); err != nil {
+		t.Fatalf("WriteSnapshot returned error: %v", err)
+	}
+	if err := syncService.WriteLocalEnv(map[string]string{"A": "1"}); err != nil {
+		t.Fatalf("WriteLocalEnv returned error: %v", err)
+	}
+
+	uc := &pushUseCase{}
+	_, err := uc.pushTarget(syncTarget{service: syncService, deleteThreshold: 0}, SyncOptions{})
+	if !errors.Is(err, ErrSyncNotConfirmed) {
+		t.Fatalf("Expected ErrSyncNotConfirmed, got %v", err)
+	}
+	if repo.written != 0 {
+		t.Errorf("Expected nothing to be written, got %d writes", repo.written)
+	}
+
+	snapshot, err := syncService.ReadSnapshot()
+	if err != nil {
+		t.Fatalf("ReadSnapshot returned error: %v", err)
+	}
+	if _, ok := snapshot.Variables["B"]; !ok {
+		t.Errorf("Expected the snapshot to keep B, got %v", snapshot.Variables)
+	}
+}
+
+// fakeEnvTypeService holds the environment types of every app
+type fakeEnvTypeService struct {
+	envTypes []domain.EnvType
+}
+
+func (s *fakeEnvTypeService) CreateEnvType(envType *domain.EnvType) (domain.EnvType, error) {
+	return *envType, nil
+}
+
+func (s *fakeEnvTypeService) GetEnvTypeByID(id string) (domain.EnvType, error) {
+	envType, _ := domain.FindEnvType(s.envTypes, id)
+	return envType, nil
+}
+
+func (s *fakeEnvTypeService) GetEnvTypesByAppID(appID string) ([]domain.EnvType, error) {
+	var envTypes []domain.EnvType
+	for _, envType := range s.envTypes {
+		if envType.AppID == appID {
+			envTypes = append(envTypes, envType)
+		}
+	}
+	return envTypes, nil
+}
+
+func (s *fakeEnvTypeService) DeleteEnvType(id string) error {
+	return nil
+}
+
+func TestResolveTargetsDeleteThreshold(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	envTypes := &fakeEnvTypeService{envTypes: []domain.EnvType{{ID: "dev", AppID: "app", Name: "dev"}}}
+
+	tests := []struct {
+		config   string
+		expected int
+	}{
+		{config: "", expected: constants.DefaultDeleteThreshold},
+		{config: "delete_threshold = 0\n", expected: constants.DefaultDeleteThreshold},
+		{config: "delete_threshold = 3\n", expected: 3},
+		{config: "delete_threshold = -1\n", expected: -1},
+	}
+	for _, tt := range tests {
+		configPath := filepath.Join(t.TempDir(), constants.DefaultProjectConfig)
+		data := "app_id = \"app\"\nenv_type_id = \"dev\"\n" + tt.config
+		if err := os.WriteFile(configPath, []byte(data), 0600); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+
+		targets, err := resolveTargets(envTypes, SyncOptions{ConfigPath: configPath})
+		if err != nil {
+			t.Fatalf("resolveTargets returned error: %v", err)
+		}
+		if len(targets) != 1 || targets[0].deleteThreshold != tt.expected {
+			t.Errorf("Expected a threshold of %d for %q, got %+v", tt.expected, tt.config, targets)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package sync
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/EnvSync-Cloud/envsync-cli/internal/constants"
+	"github.com/EnvSync-Cloud/envsync-cli/internal/domain"
+	"github.com/EnvSync-Cloud/envsync-cli/internal/presentation/tui/factory"
+	"github.com/EnvSync-Cloud/envsync-cli/internal/services"
+)
+
+type listBackupsUseCase struct{}
+
+func NewListBackupsUseCase() ListBackupsUseCase {
+	return &listBackupsUseCase{}
+}
+
+func (uc *listBackupsUseCase) Execute(ctx context.Context, opts RestoreOptions) ([]domain.EnvBackup, error) {
+	syncServices, err := localSyncServices(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []domain.EnvBackup
+	for _, syncService := range syncServices {
+		fileBackups, err := syncService.ListBackups()
+		if err != nil {
+			return nil, NewFileSystemError("failed to list backups", err)
+		}
+		backups = append(backups, fileBackups...)
+	}
+
+	return backups, nil
+}
+
+type restoreUseCase struct {
+	tui *factory.SyncFactory
+}
+
+func NewRestoreUseCase() RestoreUseCase {
+	tui := factory.NewSyncFactory()
+	return &restoreUseCase{
+		tui: tui,
+	}
+}
+
+func (uc *restoreUseCase) Execute(ctx context.Context, opts RestoreOptions) (RestoreResponse, error) {
+	syncServices, err := localSyncServices(opts)
+	if err != nil {
+		return RestoreResponse{}, err
+	}
+	if len(syncServices) > 1 {
+		return RestoreResponse{}, NewValidationError("several env files are configured, choose one with --file", "", nil)
+	}
+	syncService := syncServices[0]
+
+	backups, err := syncService.ListBackups()
+	if err != nil {
+		return RestoreResponse{}, NewFileSystemError("failed to list backups", err)
+	}
+
+	backup, err := selectBackup(backups, opts.At)
+	if err != nil {
+		return RestoreResponse{}, err
+	}
+
+	changes, err := uc.calculateRestoreDiff(syncService, backup)
+	if err != nil {
+		return RestoreResponse{}, err
+	}
+
+	response := RestoreResponse{
+		Backup:  backup,
+		Added:   changes.Added,
+		Updated: changes.Updated,
+		Deleted: changes.Deleted,
+		DryRun:  opts.DryRun,
+	}
+
+	if opts.DryRun {
+		return response, nil
+	}
+
+	if !opts.Confirmed {
+		if !opts.Interactive {
+			return RestoreResponse{}, NewPermissionError(
+				fmt.Sprintf("restoring overwrites %s, pass --yes to confirm", backup.EnvFile), ErrSyncNotConfirmed)
+		}
+		if err := uc.tui.ConfirmRestoreTUI(backup, changes); err != nil {
+			return RestoreResponse{}, err
+		}
+	}
+
+	if err := syncService.RestoreBackup(backup); err != nil {
+		return RestoreResponse{}, NewFileSystemError("failed to restore backup", err)
+	}
+
+	return response, nil
+}
+
+// calculateRestoreDiff returns the changes restoring the backup makes to the current file
+func (uc *restoreUseCase) calculateRestoreDiff(syncService services.SyncService, backup domain.EnvBackup) (domain.ChangeSet, error) {
+	current, err := syncService.ReadLocalEnv()
+	if err != nil {
+		return domain.ChangeSet{}, NewFileSystemError("failed to read local environment variables", err)
+	}
+
+	restored, err := syncService.ReadBackup(backup)
+	if err != nil {
+		return domain.ChangeSet{}, NewCorruptedError("failed to read backup "+backup.ID, err)
+	}
+
+	// The backup takes the place of the local side, the current file is what it replaces
+	currentVars := make(map[string]domain.EnvironmentVariable, len(current))
+	for k, v := range current {
+		currentVars[k] = domain.EnvironmentVariable{Key: k, Value: v}
+	}
+	envSync := domain.NewEnvironmentSync(restored, currentVars)
+	envSync.CalculateDiff()
+
+	changes := domain.ChangeSet{
+		Added:   envSync.ToAdd,
+		Updated: envSync.ToUpdate,
+	}
+	for _, key := range envSync.ToDelete {
+		changes.Deleted = append(changes.Deleted, currentVars[key])
+	}
+
+	byKey := func(a, b domain.EnvironmentVariable) int { return cmp.Compare(a.Key, b.Key) }
+	slices.SortFunc(changes.Added, byKey)
+	slices.SortFunc(changes.Updated, byKey)
+	slices.SortFunc(changes.Deleted, byKey)
+
+	return changes, nil
+}
+
+// selectBackup returns the newest backup, or the one whose ID starts with at
+func selectBackup(backups []domain.EnvBackup, at string) (domain.EnvBackup, error) {
+	if len(backups) == 0 {
+		return domain.EnvBackup{}, NewNotFoundError("no backups found", ErrSyncFileNotFound)
+	}
+	if at == "" {
+		return backups[0], nil
+	}
+
+	var matches []domain.EnvBackup
+	for _, b := range backups {
+		if b.ID == at {
+			return b, nil
+		}
+		if strings.HasPrefix(b.ID, at) {
+			matches = append(matches, b)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return domain.EnvBackup{}, NewNotFoundError("no backup found at "+at, ErrSyncFileNotFound)
+	case 1:
+		return matches[0], nil
+	default:
+		return domain.EnvBackup{}, NewValidationError(
+			fmt.Sprintf("%d backups match %s, use a longer timestamp", len(matches), at), "", nil)
+	}
+}
+
+// localSyncServices returns sync services for the configured env files,
+// limited to opts.EnvFile when set. Environment types are not resolved since
+// only local files are involved.
+func localSyncServices(opts RestoreOptions) ([]services.SyncService, error) {
+	cfg, err := services.ReadSyncConfig(opts.ConfigPath)
+	if err != nil {
+		return nil, NewCorruptedError("failed to read configuration file", err)
+	}
+
+	var syncServices []services.SyncService
+	for _, file := range cfg.EnvFiles() {
+		if file == "" {
+			file = constants.DefaultEnvFile
+		}
+		if opts.EnvFile != "" && filepath.Clean(opts.EnvFile) != filepath.Clean(file) {
+			continue
+		}
+		// The environment type is only known without a lookup for a single env file
+		target := domain.SyncTarget{EnvFile: file}
+		if len(cfg.Files) == 0 {
+			target.EnvType = domain.EnvType{ID: cfg.EnvTypeID}
+		}
+		syncServices = append(syncServices, services.NewSyncServiceForTarget(opts.ConfigPath, cfg, target))
+	}
+
+	if len(syncServices) == 0 {
+		return nil, NewNotFoundError(fmt.Sprintf("env file %s is not configured", opts.EnvFile), ErrSyncFileNotFound)
+	}
+
+	return syncServices, nil
+}
@@ -0,0 +1,85 @@
+package sync
+
+import (
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/EnvSync-Cloud/envsync-cli/internal/domain"
+	"github.com/EnvSync-Cloud/envsync-cli/internal/repository/requests"
+	"github.com/EnvSync-Cloud/envsync-cli/internal/repository/responses"
+	"github.com/EnvSync-Cloud/envsync-cli/internal/services"
+)
+
+// fakeEnvRepository holds the remote variables in memory
+type fakeEnvRepository struct {
+	env     map[string]string
+	written int
+}
+
+func (r *fakeEnvRepository) GetAllEnv() ([]responses.EnvironmentVariable, error) {
+	var env []responses.EnvironmentVariable
+	for key, value := range r.env {
+		env = append(env, responses.EnvironmentVariable{Key: key, Value: value})
+	}
+	return env, nil
+}
+
+func (r *fakeEnvRepository) BatchCreateEnv(req requests.BatchSyncEnvRequest) error {
+	r.written++
+	return nil
+}
+
+func (r *fakeEnvRepository) BatchUpdateEnv(req requests.BatchSyncEnvRequest) error {
+	r.written++
+	return nil
+}
+
+func (r *fakeEnvRepository) BatchDeleteEnv(req requests.BatchDeleteRequest) error {
+	r.written++
+	return nil
+}
+
+func TestPushAfterRestoreKeepsRemoteChanges(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	repo := &fakeEnvRepository{env: map[string]string{"A": "1", "B": "3", "C": "4"}}
+	syncService := services.NewSyncServiceFromRepository(repo, domain.SyncConfig{AppID: "app", EnvTypeID: "dev"})
+
+	// A pull brought B and C in after the file had been written, the old file was backed up
+	if err := syncService.WriteLocalEnv(map[string]string{"A": "1", "B": "2"}); err != nil {
+		t.Fatalf("WriteLocalEnv returned error: %v", err)
+	}
+	if err := syncService.WriteLocalEnv(maps.Clone(repo.env)); err != nil {
+		t.Fatalf("WriteLocalEnv returned error: %v", err)
+	}
+	if err := syncService.WriteSnapshot(maps.Clone(repo.env)); err != nil {
+		t.Fatalf("WriteSnapshot returned error: %v", err)
+	}
+
+	backups, err := syncService.ListBackups()
+	if err != nil || len(backups) == 0 {
+		t.Fatalf("Expected a backup, got %v (%v)", backups, err)
+	}
+	if err := syncService.RestoreBackup(backups[0]); err != nil {
+		t.Fatalf("RestoreBackup returned error: %v", err)
+	}
+
+	uc := &pushUseCase{}
+	diff, err := uc.pushTarget(syncTarget{service: syncService, deleteThreshold: -1}, SyncOptions{})
+	if err != nil {
+		t.Fatalf("pushTarget returned error: %v", err)
+	}
+
+	// Without a base, B is a conflict and C is not taken for a local deletion
+	if diff.HasChanges() || repo.written != 0 {
+		t.Errorf("Expected the push to change nothing, got %+v", diff)
+	}
+	conflicts := make([]string, len(diff.Conflicts))
+	for i, c := range diff.Conflicts {
+		conflicts[i] = c.Key
+	}
+	if !slices.Equal(conflicts, []string{"B"}) {
+		t.Errorf("Expected B to conflict, got %v", conflicts)
+	}
+}
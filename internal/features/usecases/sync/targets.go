@@ -0,0 +1,76 @@
+package sync
+
+import (
+	"slices"
+
+	"github.com/EnvSync-Cloud/envsync-cli/internal/constants"
+	"github.com/EnvSync-Cloud/envsync-cli/internal/domain"
+	"github.com/EnvSync-Cloud/envsync-cli/internal/services"
+)
+
+// syncTarget is a resolved env file together with the service that syncs it
+type syncTarget struct {
+	domain.SyncTarget
+	service services.SyncService
+	filter  domain.KeyFilter
+	// deleteThreshold is the number of remote deletions allowed without confirmation
+	deleteThreshold int
+}
+
+// resolveTargets reads the project configuration and returns the env files it syncs
+func resolveTargets(envTypeService services.EnvTypeService, opts SyncOptions) ([]syncTarget, error) {
+	cfg, err := services.ReadSyncConfig(opts.ConfigPath)
+	if err != nil {
+		return nil, NewCorruptedError("failed to read configuration file", err)
+	}
+
+	deleteThreshold := cfg.DeleteThreshold
+	if deleteThreshold == 0 {
+		deleteThreshold = constants.DefaultDeleteThreshold
+	}
+
+	filter := keyFilter(cfg, opts.Only, opts.Exclude)
+	if err := filter.Validate(); err != nil {
+		return nil, NewValidationError("invalid key filter", "", err)
+	}
+
+	envTypes, err := envTypeService.GetEnvTypesByAppID(cfg.AppID)
+	if err != nil {
+		return nil, NewServiceError("failed to fetch environment types", err)
+	}
+
+	targets, err := cfg.Targets(envTypes)
+	if err != nil {
+		return nil, NewValidationError("invalid env file configuration", "", err)
+	}
+
+	resolved := make([]syncTarget, len(targets))
+	for i, t := range targets {
+		if t.EnvFile == "" {
+			t.EnvFile = constants.DefaultEnvFile
+		}
+		resolved[i] = syncTarget{
+			SyncTarget: t,
+			service:    services.NewSyncServiceForTarget(opts.ConfigPath, cfg, t),
+			filter:     filter,
+
+			deleteThreshold: deleteThreshold,
+		}
+	}
+
+	return resolved, nil
+}
+
+// keyFilter combines the key filters of the configuration and the command line.
+// --only replaces the configured include list, --exclude adds to the configured
+// exclude list so machine-local keys stay protected.
+func keyFilter(cfg domain.SyncConfig, only, exclude []string) domain.KeyFilter {
+	filter := domain.KeyFilter{
+		Include: cfg.Include,
+		Exclude: slices.Concat(cfg.Exclude, exclude),
+	}
+	if len(only) > 0 {
+		filter.Include = only
+	}
+	return filter
+}
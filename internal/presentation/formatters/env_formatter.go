@@ -1,5 +1,7 @@
 package formatters
 
+import "io"
+
 type EnvFormatter struct {
 	*BaseFormatter
 }
@@ -10,3 +12,8 @@ func NewEnvFormatter() *EnvFormatter {
 		BaseFormatter: base,
 	}
 }
+
+// FormatPromotePlan prints the variables a promotion creates and updates
+func (f *EnvFormatter) FormatPromotePlan(writer io.Writer, plan Plan, target string) error {
+	return formatPlan(writer, plan, "Promote", target)
+}
@@ -1,5 +1,16 @@
 package formatters
 
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/EnvSync-Cloud/envsync-cli/internal/domain"
+	"github.com/EnvSync-Cloud/envsync-cli/internal/presentation/style"
+	"github.com/EnvSync-Cloud/envsync-cli/internal/utils"
+)
+
 type SyncFormatter struct {
 	*BaseFormatter
 }
@@ -10,3 +21,176 @@ func NewSyncFormatter() *SyncFormatter {
 		BaseFormatter: base,
 	}
 }
+
+// PlanEntry is a single masked change in a sync plan
+type PlanEntry struct {
+	Key   string `json:"key"`
+	Value string `json:"value,omitempty"`
+}
+
+// Plan is the JSON representation of a sync plan
+type Plan struct {
+	Operation  string      `json:"operation"`
+	EnvFile    string      `json:"env_file,omitempty"`
+	EnvType    string      `json:"env_type,omitempty"`
+	DryRun     bool        `json:"dry_run"`
+	HasChanges bool        `json:"has_changes"`
+	Add        []PlanEntry `json:"add"`
+	Update     []PlanEntry `json:"update"`
+	Delete     []PlanEntry `json:"delete"`
+	Conflicts  []string    `json:"conflicts"`
+	Warnings   []string    `json:"warnings,omitempty"`
+}
+
+// NewPlan builds a plan for the given changes with all values masked
+func NewPlan(operation string, dryRun bool, changes domain.ChangeSet, conflicts []domain.EnvironmentConflict, warnings []string) Plan {
+	plan := Plan{
+		Operation:  operation,
+		DryRun:     dryRun,
+		HasChanges: !changes.IsEmpty(),
+		Add:        make([]PlanEntry, 0, len(changes.Added)),
+		Update:     make([]PlanEntry, 0, len(changes.Updated)),
+		Delete:     make([]PlanEntry, 0, len(changes.Deleted)),
+		Conflicts:  make([]string, 0, len(conflicts)),
+		Warnings:   warnings,
+	}
+
+	for _, v := range changes.Added {
+		plan.Add = append(plan.Add, PlanEntry{Key: v.Key, Value: utils.MaskValue(v.Value)})
+	}
+	for _, v := range changes.Updated {
+		plan.Update = append(plan.Update, PlanEntry{Key: v.Key, Value: utils.MaskValue(v.Value)})
+	}
+	for _, v := range changes.Deleted {
+		plan.Delete = append(plan.Delete, PlanEntry{Key: v.Key})
+	}
+	for _, c := range conflicts {
+		plan.Conflicts = append(plan.Conflicts, c.Key)
+	}
+
+	return plan
+}
+
+// FormatPlan prints a plan of adds, updates and deletes similar to `terraform plan`
+func (f *SyncFormatter) FormatPlan(writer io.Writer, plan Plan, title, target string) error {
+	return formatPlan(writer, plan, title, target)
+}
+
+func formatPlan(writer io.Writer, plan Plan, title, target string) error {
+	var output strings.Builder
+
+	output.WriteString(style.TitleStyle.Render(fmt.Sprintf("📋 %s plan (%s)", title, target)))
+	output.WriteString("\n\n")
+
+	for _, e := range plan.Add {
+		output.WriteString(style.SuccessStyle.Render(fmt.Sprintf("  + %s = %s", e.Key, e.Value)) + "\n")
+	}
+	for _, e := range plan.Update {
+		output.WriteString(style.WarningStyle.Render(fmt.Sprintf("  ~ %s = %s", e.Key, e.Value)) + "\n")
+	}
+	for _, e := range plan.Delete {
+		output.WriteString(style.ErrorStyle.Render(fmt.Sprintf("  - %s", e.Key)) + "\n")
+	}
+	for _, key := range plan.Conflicts {
+		output.WriteString(style.ErrorStyle.Render(fmt.Sprintf("  ! %s (conflict, left untouched)", key)) + "\n")
+	}
+
+	if !plan.HasChanges && len(plan.Conflicts) == 0 {
+		output.WriteString(style.DescriptionStyle.Render("  No changes.") + "\n")
+	}
+
+	output.WriteString(fmt.Sprintf("\nPlan: %d to add, %d to change, %d to delete, %d conflicting.\n",
+		len(plan.Add), len(plan.Update), len(plan.Delete), len(plan.Conflicts)))
+
+	for _, warning := range plan.Warnings {
+		output.WriteString(style.WarningStyle.Render("⚠️  "+warning) + "\n")
+	}
+
+	_, err := writer.Write([]byte(output.String()))
+	return err
+}
+
+// FormatBackups lists the backups of the local env files, newest first
+func (f *SyncFormatter) FormatBackups(writer io.Writer, backups []domain.EnvBackup) error {
+	if len(backups) == 0 {
+		_, err := writer.Write([]byte("No backups found.\n"))
+		return err
+	}
+
+	var output strings.Builder
+
+	output.WriteString(style.TitleStyle.Render("🗄️  Backups"))
+	output.WriteString("\n\n")
+
+	for _, b := range backups {
+		output.WriteString(fmt.Sprintf("  %s  %s  %s\n",
+			style.Bold(b.ID),
+			b.CreatedAt.Local().Format("2006-01-02 15:04:05"),
+			style.DescriptionStyle.Render(b.EnvFile)))
+	}
+
+	_, err := writer.Write([]byte(output.String()))
+	return err
+}
+
+// DiffChangeEntry is a key whose value differs between both sides of a diff
+type DiffChangeEntry struct {
+	Key  string `json:"key"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Diff is the JSON representation of the differences between two sets of variables
+type Diff struct {
+	From       string            `json:"from"`
+	To         string            `json:"to"`
+	HasChanges bool              `json:"has_changes"`
+	Added      []PlanEntry       `json:"added"`
+	Changed    []DiffChangeEntry `json:"changed"`
+	Removed    []PlanEntry       `json:"removed"`
+}
+
+// FormatDiff prints a diff as a unified view ordered by key, lines of From
+// prefixed with - and lines of To prefixed with +
+func (f *SyncFormatter) FormatDiff(writer io.Writer, diff Diff) error {
+	var output strings.Builder
+
+	output.WriteString(style.ErrorStyle.Render("--- "+diff.From) + "\n")
+	output.WriteString(style.SuccessStyle.Render("+++ "+diff.To) + "\n")
+
+	lines := make(map[string][]string, len(diff.Added)+len(diff.Changed)+len(diff.Removed))
+	for _, e := range diff.Removed {
+		lines[e.Key] = []string{style.ErrorStyle.Render(fmt.Sprintf("-%s=%s", e.Key, e.Value))}
+	}
+	for _, e := range diff.Changed {
+		lines[e.Key] = []string{
+			style.ErrorStyle.Render(fmt.Sprintf("-%s=%s", e.Key, e.From)),
+			style.SuccessStyle.Render(fmt.Sprintf("+%s=%s", e.Key, e.To)),
+		}
+	}
+	for _, e := range diff.Added {
+		lines[e.Key] = []string{style.SuccessStyle.Render(fmt.Sprintf("+%s=%s", e.Key, e.Value))}
+	}
+
+	keys := make([]string, 0, len(lines))
+	for key := range lines {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		for _, line := range lines[key] {
+			output.WriteString(line + "\n")
+		}
+	}
+
+	if diff.HasChanges {
+		output.WriteString(fmt.Sprintf("\n%d added, %d changed, %d removed.\n",
+			len(diff.Added), len(diff.Changed), len(diff.Removed)))
+	} else {
+		output.WriteString(style.DescriptionStyle.Render("No differences.") + "\n")
+	}
+
+	_, err := writer.Write([]byte(output.String()))
+	return err
+}
@@ -0,0 +1,195 @@
+package component
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/EnvSync-Cloud/envsync-cli/internal/presentation/style"
+)
+
+// ConflictChoice is the resolution picked for a single conflicting key
+type ConflictChoice int
+
+const (
+	ConflictSkip ConflictChoice = iota
+	ConflictKeepLocal
+	ConflictTakeRemote
+	ConflictEdit
+)
+
+func (c ConflictChoice) String() string {
+	switch c {
+	case ConflictKeepLocal:
+		return "keep local"
+	case ConflictTakeRemote:
+		return "take remote"
+	case ConflictEdit:
+		return "edited"
+	default:
+		return "skip"
+	}
+}
+
+// ConflictItem is a conflicting key with the values already prepared for
+// display, masked like in the sync plan
+type ConflictItem struct {
+	Key           string
+	BaseDisplay   string
+	LocalDisplay  string
+	RemoteDisplay string
+}
+
+// ConflictResolverModel is a Bubble Tea model that lets the user pick a resolution for each conflict
+type ConflictResolverModel struct {
+	title     string
+	items     []ConflictItem
+	choices   []ConflictChoice
+	edited    []string
+	cursor    int
+	editing   bool
+	input     textinput.Model
+	confirmed bool
+}
+
+func NewConflictResolverModel(title string, items []ConflictItem) *ConflictResolverModel {
+	// The new value is hidden like the others until the user shows it
+	input := textinput.New()
+	input.Prompt = "New value: "
+	input.CharLimit = 0
+	input.EchoMode = textinput.EchoPassword
+
+	return &ConflictResolverModel{
+		title:   title,
+		items:   items,
+		choices: make([]ConflictChoice, len(items)),
+		edited:  make([]string, len(items)),
+		input:   input,
+	}
+}
+
+func (m *ConflictResolverModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *ConflictResolverModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	if m.editing {
+		return m.updateEditing(keyMsg)
+	}
+
+	switch keyMsg.String() {
+	case "ctrl+c", "q", "esc":
+		return m, tea.Quit
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(m.items)-1 {
+			m.cursor++
+		}
+	case "l":
+		m.choices[m.cursor] = ConflictKeepLocal
+	case "r":
+		m.choices[m.cursor] = ConflictTakeRemote
+	case "s":
+		m.choices[m.cursor] = ConflictSkip
+	case "e":
+		m.editing = true
+		m.input.SetValue(m.edited[m.cursor])
+		return m, m.input.Focus()
+	case "enter":
+		m.confirmed = true
+		return m, tea.Quit
+	}
+
+	return m, nil
+}
+
+func (m *ConflictResolverModel) updateEditing(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		m.stopEditing()
+		return m, nil
+	case "enter":
+		m.edited[m.cursor] = m.input.Value()
+		m.choices[m.cursor] = ConflictEdit
+		m.stopEditing()
+		return m, nil
+	case "tab":
+		if m.input.EchoMode == textinput.EchoPassword {
+			m.input.EchoMode = textinput.EchoNormal
+		} else {
+			m.input.EchoMode = textinput.EchoPassword
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+// stopEditing leaves the input, hiding the value again for the next edit
+func (m *ConflictResolverModel) stopEditing() {
+	m.editing = false
+	m.input.Blur()
+	m.input.EchoMode = textinput.EchoPassword
+}
+
+func (m *ConflictResolverModel) View() string {
+	var b strings.Builder
+
+	b.WriteString(style.TitleStyle.Render(m.title))
+	b.WriteString("\n\n")
+
+	for i, item := range m.items {
+		cursor := "  "
+		if i == m.cursor {
+			cursor = style.CursorStyle.Render("▶ ")
+		}
+
+		choice := style.DescriptionStyle.Render("[" + m.choices[i].String() + "]")
+		if m.choices[i] != ConflictSkip {
+			choice = style.SuccessStyle.Render("[" + m.choices[i].String() + "]")
+		}
+
+		b.WriteString(fmt.Sprintf("%s%s %s\n", cursor, style.Bold(item.Key), choice))
+		if item.BaseDisplay != "" {
+			b.WriteString(fmt.Sprintf("     base:   %s\n", item.BaseDisplay))
+		}
+		b.WriteString(fmt.Sprintf("     local:  %s\n", item.LocalDisplay))
+		b.WriteString(fmt.Sprintf("     remote: %s\n", item.RemoteDisplay))
+	}
+
+	b.WriteString("\n")
+	if m.editing {
+		b.WriteString(m.input.View())
+		b.WriteString("\n")
+		b.WriteString(style.HelpStyle.Render("ENTER to save • TAB to show/hide • ESC to cancel"))
+	} else {
+		b.WriteString(style.HelpStyle.Render("↑/↓ to navigate • l keep local • r take remote • e edit • s skip • ENTER to apply • q to abort"))
+	}
+	b.WriteString("\n")
+
+	return b.String()
+}
+
+// Confirmed returns true if the user applied the resolutions instead of aborting
+func (m *ConflictResolverModel) Confirmed() bool {
+	return m.confirmed
+}
+
+// Choice returns the resolution picked for the item at index, along with the edited value
+func (m *ConflictResolverModel) Choice(index int) (ConflictChoice, string) {
+	return m.choices[index], m.edited[index]
+}
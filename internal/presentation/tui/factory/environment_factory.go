@@ -2,10 +2,13 @@ package factory
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/EnvSync-Cloud/envsync-cli/internal/domain"
 	"github.com/EnvSync-Cloud/envsync-cli/internal/presentation/tui/component"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
 )
 
 type EnvFactory struct{}
@@ -47,3 +50,53 @@ func (f *EnvFactory) SelectEnvironmentTUI(envs []domain.EnvType) (domain.EnvType
 	}
 	return selected[0], nil
 }
+
+// ConfirmPromoteTUI shows the plan of a promotion and asks the user to apply
+// it. A protected environment type makes the user type its name instead.
+// tea.ErrProgramKilled is returned when the user declines or aborts.
+func (f *EnvFactory) ConfirmPromoteTUI(fromName string, to domain.EnvType, changes domain.ChangeSet, skipped, missing []string) error {
+	summary := fmt.Sprintf("Promoting from %s:\n%s\n", fromName, promotePreview(changes, skipped, missing))
+	if to.IsProtected {
+		return confirmProtectedEnv(to.Name, summary)
+	}
+
+	var confirm bool
+
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewConfirm().
+				Title(fmt.Sprintf("Promote to %s?", to.Name)).
+				Description(summary).
+				Affirmative("Promote").
+				Negative("Cancel").
+				Value(&confirm),
+		),
+	).WithTheme(huh.ThemeCharm())
+
+	if err := form.Run(); err != nil {
+		if errors.Is(err, huh.ErrUserAborted) {
+			return tea.ErrProgramKilled
+		}
+		return err
+	}
+
+	if !confirm {
+		return tea.ErrProgramKilled
+	}
+
+	return nil
+}
+
+// promotePreview lists the changes of a promotion with the values masked,
+// followed by the keys it leaves alone
+func promotePreview(changes domain.ChangeSet, skipped, missing []string) string {
+	var preview strings.Builder
+	preview.WriteString(changePreview(changes))
+	for _, key := range skipped {
+		preview.WriteString(fmt.Sprintf("\n= %s (already set, skipped)", key))
+	}
+	for _, key := range missing {
+		preview.WriteString(fmt.Sprintf("\n? %s (not in the source)", key))
+	}
+	return preview.String()
+}
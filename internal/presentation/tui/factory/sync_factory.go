@@ -0,0 +1,194 @@
+package factory
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
+
+	"github.com/EnvSync-Cloud/envsync-cli/internal/domain"
+	"github.com/EnvSync-Cloud/envsync-cli/internal/presentation/tui/component"
+	"github.com/EnvSync-Cloud/envsync-cli/internal/utils"
+)
+
+type SyncFactory struct{}
+
+func NewSyncFactory() *SyncFactory {
+	return &SyncFactory{}
+}
+
+// ResolveConflictsTUI lets the user pick a resolution for each conflict.
+// Skipped keys are not part of the result. tea.ErrProgramKilled is returned
+// when the user aborts.
+func (f *SyncFactory) ResolveConflictsTUI(conflicts []domain.EnvironmentConflict) ([]domain.ConflictResolution, error) {
+	items := make([]component.ConflictItem, len(conflicts))
+	for i, c := range conflicts {
+		items[i] = component.ConflictItem{
+			Key:           c.Key,
+			BaseDisplay:   displayConflictBase(c),
+			LocalDisplay:  displayConflictValue(c.LocalValue, c.LocalDeleted),
+			RemoteDisplay: displayConflictValue(c.RemoteValue, c.RemoteDeleted),
+		}
+	}
+
+	model := component.NewConflictResolverModel("⚔️  Resolve sync conflicts", items)
+
+	p := tea.NewProgram(model, tea.WithAltScreen())
+	finalModel, err := p.Run()
+	if err != nil {
+		return nil, err
+	}
+
+	resolver := finalModel.(*component.ConflictResolverModel)
+	if !resolver.Confirmed() {
+		return nil, tea.ErrProgramKilled
+	}
+
+	var resolutions []domain.ConflictResolution
+	for i, c := range conflicts {
+		choice, edited := resolver.Choice(i)
+		switch choice {
+		case component.ConflictKeepLocal:
+			resolutions = append(resolutions, c.KeepLocal())
+		case component.ConflictTakeRemote:
+			resolutions = append(resolutions, c.TakeRemote())
+		case component.ConflictEdit:
+			resolutions = append(resolutions, domain.ConflictResolution{Key: c.Key, Value: edited})
+		}
+	}
+
+	return resolutions, nil
+}
+
+// ConfirmProtectedPushTUI asks the user to type the name of a protected
+// environment type before pushing to it. tea.ErrProgramKilled is returned
+// when the user aborts.
+func (f *SyncFactory) ConfirmProtectedPushTUI(envName, summary string) error {
+	return confirmProtectedEnv(envName, summary)
+}
+
+// ConfirmDeletesTUI asks the user to confirm a push that deletes many variables.
+// tea.ErrProgramKilled is returned when the user declines or aborts.
+func (f *SyncFactory) ConfirmDeletesTUI(envName string, count int) error {
+	var confirm bool
+
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewConfirm().
+				Title(fmt.Sprintf("Delete %d variables from %s?", count, envName)).
+				Affirmative("Delete").
+				Negative("Cancel").
+				Value(&confirm),
+		),
+	).WithTheme(huh.ThemeCharm())
+
+	if err := form.Run(); err != nil {
+		if errors.Is(err, huh.ErrUserAborted) {
+			return tea.ErrProgramKilled
+		}
+		return err
+	}
+
+	if !confirm {
+		return tea.ErrProgramKilled
+	}
+
+	return nil
+}
+
+// ConfirmRestoreTUI shows the changes a restore makes and asks the user to
+// confirm. tea.ErrProgramKilled is returned when the user declines or aborts.
+func (f *SyncFactory) ConfirmRestoreTUI(backup domain.EnvBackup, changes domain.ChangeSet) error {
+	var confirm bool
+
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewConfirm().
+				Title(fmt.Sprintf("Restore %s from %s?", backup.EnvFile, backup.CreatedAt.Local().Format("2006-01-02 15:04:05"))).
+				Description(changePreview(changes)).
+				Affirmative("Restore").
+				Negative("Cancel").
+				Value(&confirm),
+		),
+	).WithTheme(huh.ThemeCharm())
+
+	if err := form.Run(); err != nil {
+		if errors.Is(err, huh.ErrUserAborted) {
+			return tea.ErrProgramKilled
+		}
+		return err
+	}
+
+	if !confirm {
+		return tea.ErrProgramKilled
+	}
+
+	return nil
+}
+
+// confirmProtectedEnv makes the user type the name of a protected
+// environment type before it is changed
+func confirmProtectedEnv(envName, summary string) error {
+	var typed string
+
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewInput().
+				Title(fmt.Sprintf("%s is a protected environment", envName)).
+				Description(summary + "\nType the environment name to continue.").
+				Value(&typed).
+				Validate(func(str string) error {
+					if str != envName {
+						return fmt.Errorf("type %s to confirm", envName)
+					}
+					return nil
+				}),
+		),
+	).WithTheme(huh.ThemeCharm())
+
+	if err := form.Run(); err != nil {
+		if errors.Is(err, huh.ErrUserAborted) {
+			return tea.ErrProgramKilled
+		}
+		return err
+	}
+
+	return nil
+}
+
+// changePreview lists the changes one per line with the values masked
+func changePreview(changes domain.ChangeSet) string {
+	if changes.IsEmpty() {
+		return "No variable changes."
+	}
+
+	var preview strings.Builder
+	for _, v := range changes.Added {
+		preview.WriteString(fmt.Sprintf("+ %s = %s\n", v.Key, utils.MaskValue(v.Value)))
+	}
+	for _, v := range changes.Updated {
+		preview.WriteString(fmt.Sprintf("~ %s = %s\n", v.Key, utils.MaskValue(v.Value)))
+	}
+	for _, v := range changes.Deleted {
+		preview.WriteString(fmt.Sprintf("- %s\n", v.Key))
+	}
+	return strings.TrimSuffix(preview.String(), "\n")
+}
+
+// displayConflictBase returns the masked value both sides started from, or
+// nothing when the key is new on both sides
+func displayConflictBase(c domain.EnvironmentConflict) string {
+	if !c.InBase {
+		return ""
+	}
+	return utils.MaskValue(c.BaseValue)
+}
+
+func displayConflictValue(value string, deleted bool) string {
+	if deleted {
+		return "(deleted)"
+	}
+	return utils.MaskValue(value)
+}
@@ -0,0 +1,186 @@
+package services
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// Matcher finds any number of strings in a text in a single pass using the
+// Aho-Corasick algorithm. It is built once and safe for concurrent use.
+type Matcher struct {
+	nodes    []matcherNode
+	patterns []string
+	maxLen   int
+}
+
+type matcherNode struct {
+	next map[byte]int32
+	fail int32
+	// pattern is the index of the pattern ending at this node, or -1
+	pattern int32
+	// output is the nearest node on the fail chain where a pattern ends, or -1
+	output int32
+	// depth is the length of the pattern prefix the node stands for
+	depth int32
+}
+
+// Match is an occurrence of a pattern, text[Start:End] == Pattern
+type Match struct {
+	Start   int
+	End     int
+	Pattern string
+}
+
+// NewMatcher builds a matcher for patterns. Empty and duplicate patterns are ignored.
+func NewMatcher(patterns []string) *Matcher {
+	m := &Matcher{
+		nodes: []matcherNode{{next: map[byte]int32{}, pattern: -1, output: -1}},
+	}
+
+	for _, p := range patterns {
+		if p == "" || slices.Contains(m.patterns, p) {
+			continue
+		}
+		m.insert(p, int32(len(m.patterns)))
+		m.patterns = append(m.patterns, p)
+		m.maxLen = max(m.maxLen, len(p))
+	}
+	m.link()
+
+	return m
+}
+
+// Len returns the number of patterns
+func (m *Matcher) Len() int {
+	return len(m.patterns)
+}
+
+// MaxLen returns the length of the longest pattern
+func (m *Matcher) MaxLen() int {
+	return m.maxLen
+}
+
+func (m *Matcher) insert(pattern string, index int32) {
+	var node int32
+	for i := 0; i < len(pattern); i++ {
+		child, ok := m.nodes[node].next[pattern[i]]
+		if !ok {
+			child = int32(len(m.nodes))
+			m.nodes = append(m.nodes, matcherNode{next: map[byte]int32{}, pattern: -1, output: -1, depth: int32(i + 1)})
+			m.nodes[node].next[pattern[i]] = child
+		}
+		node = child
+	}
+	m.nodes[node].pattern = index
+}
+
+// link sets the fail and output links breadth first
+func (m *Matcher) link() {
+	queue := make([]int32, 0, len(m.nodes))
+	for _, child := range m.nodes[0].next {
+		queue = append(queue, child)
+	}
+
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+
+		for c, child := range m.nodes[node].next {
+			fail := m.step(m.nodes[node].fail, c)
+			m.nodes[child].fail = fail
+			if m.nodes[fail].pattern != -1 {
+				m.nodes[child].output = fail
+			} else {
+				m.nodes[child].output = m.nodes[fail].output
+			}
+			queue = append(queue, child)
+		}
+	}
+}
+
+// step follows the transition for c from node, falling back along fail links
+func (m *Matcher) step(node int32, c byte) int32 {
+	for {
+		if child, ok := m.nodes[node].next[c]; ok {
+			return child
+		}
+		if node == 0 {
+			return 0
+		}
+		node = m.nodes[node].fail
+	}
+}
+
+// PrefixLen returns the length of the longest end of text that is the start
+// of a pattern, which the text following it may complete
+func (m *Matcher) PrefixLen(text string) int {
+	var node int32
+	for i := 0; i < len(text) && len(m.patterns) > 0; i++ {
+		node = m.step(node, text[i])
+	}
+	return int(m.nodes[node].depth)
+}
+
+// FindAll returns every occurrence of every pattern in text, overlapping
+// ones included, ordered by end and then by length
+func (m *Matcher) FindAll(text string) []Match {
+	if len(m.patterns) == 0 {
+		return nil
+	}
+
+	var matches []Match
+	var node int32
+	for i := 0; i < len(text); i++ {
+		node = m.step(node, text[i])
+		for out := node; out != -1; out = m.nodes[out].output {
+			if p := m.nodes[out].pattern; p != -1 {
+				pattern := m.patterns[p]
+				matches = append(matches, Match{Start: i + 1 - len(pattern), End: i + 1, Pattern: pattern})
+			}
+		}
+	}
+	return matches
+}
+
+// Find returns the occurrences that ReplaceAll replaces: scanning from the
+// left, the longest pattern starting at each position wins and matches never
+// overlap
+func (m *Matcher) Find(text string) []Match {
+	matches := m.FindAll(text)
+	slices.SortFunc(matches, func(a, b Match) int {
+		if c := cmp.Compare(a.Start, b.Start); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.End, a.End)
+	})
+
+	selected := matches[:0]
+	end := 0
+	for _, match := range matches {
+		if match.Start >= end {
+			selected = append(selected, match)
+			end = match.End
+		}
+	}
+	return selected
+}
+
+// ReplaceAll replaces the occurrences returned by Find with the result of replace
+func (m *Matcher) ReplaceAll(text string, replace func(Match) string) string {
+	matches := m.Find(text)
+	if len(matches) == 0 {
+		return text
+	}
+
+	var b strings.Builder
+	b.Grow(len(text))
+	last := 0
+	for _, match := range matches {
+		b.WriteString(text[last:match.Start])
+		b.WriteString(replace(match))
+		last = match.End
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestMatcherReplaceAll(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		input    string
+		expected string
+	}{
+		{
+			name:     "single pattern",
+			patterns: []string{"secret"},
+			input:    "a secret and another secret",
+			expected: "a [X] and another [X]",
+		},
+		{
+			name:     "longest match wins over a shorter pattern inside it",
+			patterns: []string{"1", "supersecretvalue123"},
+			input:    "token=supersecretvalue123 retries=1",
+			expected: "token=[X] retries=[X]",
+		},
+		{
+			name:     "longest match wins over a shorter prefix",
+			patterns: []string{"abc", "abcdef"},
+			input:    "abcdef abc",
+			expected: "[X] [X]",
+		},
+		{
+			name:     "leftmost match wins over an overlapping later one",
+			patterns: []string{"abcd", "cdefgh"},
+			input:    "abcdefgh",
+			expected: "[X]efgh",
+		},
+		{
+			name:     "patterns sharing suffixes",
+			patterns: []string{"he", "she", "his", "hers"},
+			input:    "ushers said his",
+			expected: "u[X]rs said [X]",
+		},
+		{
+			name:     "empty patterns are ignored",
+			patterns: []string{"", "x"},
+			input:    "axb",
+			expected: "a[X]b",
+		},
+		{
+			name:     "no patterns",
+			patterns: nil,
+			input:    "unchanged",
+			expected: "unchanged",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMatcher(tt.patterns)
+			got := m.ReplaceAll(tt.input, func(Match) string { return "[X]" })
+			if got != tt.expected {
+				t.Errorf("ReplaceAll(%q) = %q, expected %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMatcherFindAllReportsOverlaps(t *testing.T) {
+	m := NewMatcher([]string{"he", "she", "hers"})
+
+	var got []string
+	for _, match := range m.FindAll("ushers") {
+		got = append(got, fmt.Sprintf("%s@%d", match.Pattern, match.Start))
+	}
+
+	expected := []string{"she@1", "he@2", "hers@2"}
+	if strings.Join(got, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestMatcherPrefixLen(t *testing.T) {
+	m := NewMatcher([]string{"secret", "token"})
+
+	tests := map[string]int{
+		"":               0,
+		"no match":       0,
+		"the sec":        3,
+		"my tok":         3,
+		"a secret":       6,
+		"secretive":      0,
+		"a secret and t": 1,
+	}
+	for text, expected := range tests {
+		if got := m.PrefixLen(text); got != expected {
+			t.Errorf("PrefixLen(%q): expected %d, got %d", text, expected, got)
+		}
+	}
+}
+
+// regexRedact is the previous approach: one compiled regex per value, applied in turn
+func regexRedact(text string, patterns []*regexp.Regexp) string {
+	for _, p := range patterns {
+		text = p.ReplaceAllString(text, "[REDACTED]")
+	}
+	return text
+}
+
+func benchmarkSecrets(n int) []string {
+	secrets := make([]string, n)
+	for i := range secrets {
+		secrets[i] = fmt.Sprintf("sk_live_%08x_%d", i*2654435761, i)
+	}
+	return secrets
+}
+
+func benchmarkLog(secrets []string) string {
+	var b strings.Builder
+	for i := 0; i < 2000; i++ {
+		fmt.Fprintf(&b, "2024-01-01T00:00:00Z INFO request %d handled in %dms path=/api/v1/items\n", i, i%97)
+		if i%100 == 0 {
+			fmt.Fprintf(&b, "2024-01-01T00:00:00Z DEBUG using key %s\n", secrets[i%len(secrets)])
+		}
+	}
+	return b.String()
+}
+
+func BenchmarkRedactRegexLoopManySecrets(b *testing.B) {
+	secrets := benchmarkSecrets(300)
+	input := benchmarkLog(secrets)
+
+	patterns := make([]*regexp.Regexp, len(secrets))
+	for i, s := range secrets {
+		patterns[i] = regexp.MustCompile(regexp.QuoteMeta(s))
+	}
+
+	b.SetBytes(int64(len(input)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		regexRedact(input, patterns)
+	}
+}
+
+func BenchmarkRedactMatcherManySecrets(b *testing.B) {
+	secrets := benchmarkSecrets(300)
+	input := benchmarkLog(secrets)
+
+	m := NewMatcher(secrets)
+	replace := func(Match) string { return "[REDACTED]" }
+
+	b.SetBytes(int64(len(input)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.ReplaceAll(input, replace)
+	}
+}
+
+func BenchmarkNewMatcherManySecrets(b *testing.B) {
+	secrets := benchmarkSecrets(300)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		NewMatcher(secrets)
+	}
+}
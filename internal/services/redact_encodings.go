@@ -0,0 +1,96 @@
+package services
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/EnvSync-Cloud/envsync-cli/internal/domain"
+)
+
+// minEncodedValueLength is the length a value needs before its encodings are
+// redacted too, the encodings of shorter values would match unrelated output
+const minEncodedValueLength = 8
+
+// WithEncodedVariants returns values followed by the forms they take under
+// the given encodings
+func WithEncodedVariants(values []string, encodings []domain.RedactEncoding) []string {
+	all := slices.Clone(values)
+	for _, value := range values {
+		all = append(all, EncodedVariants(value, encodings)...)
+	}
+	return all
+}
+
+// EncodedVariants returns the forms value takes under the given encodings,
+// leaving out the ones identical to the value itself
+func EncodedVariants(value string, encodings []domain.RedactEncoding) []string {
+	if len(value) < minEncodedValueLength {
+		return nil
+	}
+
+	var variants []string
+	for _, encoding := range encodings {
+		switch encoding {
+		case domain.RedactEncodingBase64:
+			variants = append(variants,
+				base64.StdEncoding.EncodeToString([]byte(value)),
+				base64.RawStdEncoding.EncodeToString([]byte(value)),
+				base64.URLEncoding.EncodeToString([]byte(value)),
+				base64.RawURLEncoding.EncodeToString([]byte(value)))
+		case domain.RedactEncodingURL:
+			variants = append(variants, url.QueryEscape(value), url.PathEscape(value))
+		case domain.RedactEncodingJSON:
+			variants = append(variants, jsonEscape(value, false), jsonEscape(value, true))
+		case domain.RedactEncodingHex:
+			encoded := hex.EncodeToString([]byte(value))
+			variants = append(variants, encoded, strings.ToUpper(encoded))
+		case domain.RedactEncodingBasicAuth:
+			variants = append(variants, basicAuthFragments(value)...)
+		}
+	}
+
+	slices.Sort(variants)
+	variants = slices.Compact(variants)
+	return slices.DeleteFunc(variants, func(v string) bool {
+		return v == value || v == ""
+	})
+}
+
+// jsonEscape returns value as it appears inside a JSON string
+func jsonEscape(value string, escapeHTML bool) string {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(escapeHTML)
+	if err := enc.Encode(value); err != nil {
+		return ""
+	}
+
+	quoted := strings.TrimSuffix(b.String(), "\n")
+	return quoted[1 : len(quoted)-1]
+}
+
+// basicAuthFragments returns the part of an Authorization: Basic header that
+// only depends on value. The header encodes "user:password" as a whole, so
+// where value starts within the encoding depends on the length of the user.
+// For each of the three possible alignments the characters shared with the
+// surrounding bytes are left out.
+func basicAuthFragments(value string) []string {
+	fragments := make([]string, 0, 3)
+	for offset := 0; offset < 3; offset++ {
+		padded := append(make([]byte, offset), value...)
+		encoded := base64.StdEncoding.EncodeToString(padded)
+
+		// Each character holds 6 bits, keep the ones made only of bits of value
+		start := (8*offset + 5) / 6
+		end := 8 * len(padded) / 6
+		if end-start >= minEncodedValueLength {
+			fragments = append(fragments, encoded[start:end])
+		}
+	}
+	return fragments
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"math"
+	"strings"
+)
+
+const (
+	// minEntropyTokenLength is the length a token needs before it is judged by
+	// its randomness, shorter ones are too often ordinary words
+	minEntropyTokenLength = 20
+	// minTokenEntropy is the Shannon entropy in bits per character above which
+	// a token is taken for a credential. Hex strings such as commit hashes top
+	// out at 4 and stay below it.
+	minTokenEntropy = 4.0
+	// maxHeldTokenLength bounds how much of an unfinished token a stream holds back
+	maxHeldTokenLength = 512
+)
+
+// redactHighEntropy replaces the tokens that look random enough to be a
+// credential, whether or not they are one of the values
+func (r *redactor) redactHighEntropy(text string, onMatch func(key string)) string {
+	var b strings.Builder
+	last := 0
+	for start := 0; start < len(text); {
+		if !isTokenByte(text[start]) {
+			start++
+			continue
+		}
+
+		end := start
+		for end < len(text) && isTokenByte(text[end]) {
+			end++
+		}
+
+		if token := text[start:end]; looksLikeCredential(token) {
+			b.WriteString(text[last:start])
+			b.WriteString(r.replacement(token, "", onMatch))
+			last = end
+		}
+		start = end
+	}
+
+	if last == 0 {
+		return text
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// looksLikeCredential reports whether token is long, mixes letters and digits
+// and has a high entropy
+func looksLikeCredential(token string) bool {
+	if len(token) < minEntropyTokenLength {
+		return false
+	}
+
+	var letters, digits bool
+	for i := 0; i < len(token); i++ {
+		switch c := token[i]; {
+		case c >= '0' && c <= '9':
+			digits = true
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+			letters = true
+		}
+	}
+
+	return letters && digits && shannonEntropy(token) >= minTokenEntropy
+}
+
+// shannonEntropy returns the entropy of s in bits per byte
+func shannonEntropy(s string) float64 {
+	var counts [256]int
+	for i := 0; i < len(s); i++ {
+		counts[s[i]]++
+	}
+
+	var entropy float64
+	n := float64(len(s))
+	for _, c := range counts {
+		if c == 0 {
+			continue
+		}
+		p := float64(c) / n
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// isTokenByte reports whether c can be part of a key or token: letters,
+// digits and the extra characters of base64 and base64url
+func isTokenByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		c == '+' || c == '/' || c == '=' || c == '-' || c == '_'
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"cmp"
+	"encoding/json"
+	"os"
+	"slices"
+	gosync "sync"
+	"time"
+)
+
+// Output streams a RedactionReport counts separately
+const (
+	RedactStreamStdout = "stdout"
+	RedactStreamStderr = "stderr"
+	// RedactStreamPTY is the terminal of a command, stdout and stderr combined
+	RedactStreamPTY = "pty"
+)
+
+// RedactionReport counts how often the value of each key was hidden from the
+// output of a command, per stream. It never holds the values themselves.
+type RedactionReport struct {
+	mu     gosync.Mutex
+	counts map[redactionCounter]int
+}
+
+type redactionCounter struct {
+	key    string
+	stream string
+}
+
+// RedactionReportEntry is the number of times the value of Key was hidden on
+// Stream. Tokens found by the entropy mode have no key.
+type RedactionReportEntry struct {
+	Key    string `json:"key,omitempty"`
+	Stream string `json:"stream"`
+	Count  int    `json:"count"`
+}
+
+// RedactionReportFile is the JSON document written by RedactionReport.WriteFile
+type RedactionReportFile struct {
+	FinishedAt time.Time              `json:"finished_at"`
+	ExitCode   int                    `json:"exit_code"`
+	Total      int                    `json:"total"`
+	Redactions []RedactionReportEntry `json:"redactions"`
+}
+
+func NewRedactionReport() *RedactionReport {
+	return &RedactionReport{counts: make(map[redactionCounter]int)}
+}
+
+func (r *RedactionReport) add(key, stream string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.counts[redactionCounter{key: key, stream: stream}]++
+}
+
+// Entries returns the counts ordered by key and stream
+func (r *RedactionReport) Entries() []RedactionReportEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entries := make([]RedactionReportEntry, 0, len(r.counts))
+	for c, count := range r.counts {
+		entries = append(entries, RedactionReportEntry{Key: c.key, Stream: c.stream, Count: count})
+	}
+	slices.SortFunc(entries, func(a, b RedactionReportEntry) int {
+		if c := cmp.Compare(a.Key, b.Key); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Stream, b.Stream)
+	})
+	return entries
+}
+
+// WriteFile writes the report of a command that exited with exitCode to path
+func (r *RedactionReport) WriteFile(path string, exitCode int) error {
+	report := RedactionReportFile{
+		FinishedAt: time.Now().UTC(),
+		ExitCode:   exitCode,
+		Redactions: r.Entries(),
+	}
+	for _, e := range report.Redactions {
+		report.Total += e.Count
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0600)
+}
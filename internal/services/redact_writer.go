@@ -0,0 +1,160 @@
+package services
+
+import (
+	"io"
+	gosync "sync"
+	"time"
+)
+
+// DefaultRedactFlushDelay is how long RedactWriter holds back output when
+// nothing else arrives, short enough for prompts and progress bars to show
+const DefaultRedactFlushDelay = 50 * time.Millisecond
+
+// RedactWriter redacts a stream of output before it reaches the underlying
+// writer. Output is usually read in chunks that can split a value anywhere,
+// so the end of each write is held back while it could be the start of a
+// value, line breaks included, until more output arrives. The held back text
+// is released once FlushDelay passes without output.
+type RedactWriter struct {
+	// FlushDelay is how long held back output waits for more output
+	FlushDelay time.Duration
+
+	mu      gosync.Mutex
+	w       io.Writer
+	matcher *Matcher
+	redact  func(string) string
+	pending []byte
+	timer   *time.Timer
+	err     error
+	// holdTokens keeps a token running into the end of the output together
+	// until it is complete, up to maxHeldTokenLength
+	holdTokens bool
+}
+
+// NewRedactWriter returns a writer that passes output through redact before
+// writing it to w. matcher finds the values redact hides.
+func NewRedactWriter(w io.Writer, matcher *Matcher, redact func(string) string) *RedactWriter {
+	return &RedactWriter{
+		FlushDelay: DefaultRedactFlushDelay,
+		w:          w,
+		matcher:    matcher,
+		redact:     redact,
+	}
+}
+
+func (rw *RedactWriter) Write(p []byte) (int, error) {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+
+	if rw.err != nil {
+		return 0, rw.err
+	}
+
+	rw.pending = append(rw.pending, p...)
+
+	// Values can span lines, a line break does not end them
+	rw.flushLocked(rw.safeCut())
+	rw.scheduleFlushLocked()
+
+	if rw.err != nil {
+		return 0, rw.err
+	}
+	return len(p), nil
+}
+
+// Flush writes everything held back
+func (rw *RedactWriter) Flush() error {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+
+	rw.flushLocked(len(rw.pending))
+	return rw.err
+}
+
+// Close flushes the held back output. The underlying writer is not closed.
+func (rw *RedactWriter) Close() error {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+
+	if rw.timer != nil {
+		rw.timer.Stop()
+	}
+	rw.flushLocked(len(rw.pending))
+	return rw.err
+}
+
+// safeCut returns how much of the pending output can be redacted and written
+// now. Everything before the cut is final: a value starting before it ends
+// before it as well, and the text after it is the start of a value.
+func (rw *RedactWriter) safeCut() int {
+	maxLen := rw.matcher.MaxLen()
+	tail := rw.pending[max(0, len(rw.pending)-maxLen):]
+	cut := len(rw.pending) - rw.matcher.PrefixLen(string(tail))
+	if cut <= 0 {
+		return 0
+	}
+
+	// Move the cut in front of any value it would split
+	for moved := true; moved && cut > 0; {
+		moved = false
+		from := max(0, cut-maxLen+1)
+		to := min(len(rw.pending), cut+maxLen-1)
+		for _, m := range rw.matcher.FindAll(string(rw.pending[from:to])) {
+			if start := from + m.Start; start < cut && from+m.End > cut {
+				cut = start
+				moved = true
+			}
+		}
+	}
+
+	if rw.holdTokens {
+		cut = rw.tokenStart(cut)
+	}
+
+	return cut
+}
+
+// tokenStart moves cut to the start of the token it splits. A token at the
+// end of the pending output may still go on, so it counts as split. Tokens
+// longer than maxHeldTokenLength are not held back.
+func (rw *RedactWriter) tokenStart(cut int) int {
+	if cut == 0 || !isTokenByte(rw.pending[cut-1]) || (cut < len(rw.pending) && !isTokenByte(rw.pending[cut])) {
+		return cut
+	}
+
+	start := cut
+	for start > 0 && isTokenByte(rw.pending[start-1]) {
+		start--
+		if cut-start > maxHeldTokenLength {
+			return cut
+		}
+	}
+	return start
+}
+
+func (rw *RedactWriter) flushLocked(n int) {
+	if n <= 0 || rw.err != nil {
+		return
+	}
+
+	text := string(rw.pending[:n])
+	rw.pending = append(rw.pending[:0], rw.pending[n:]...)
+
+	if _, err := io.WriteString(rw.w, rw.redact(text)); err != nil {
+		rw.err = err
+	}
+}
+
+func (rw *RedactWriter) scheduleFlushLocked() {
+	if len(rw.pending) == 0 {
+		return
+	}
+
+	if rw.timer == nil {
+		rw.timer = time.AfterFunc(rw.FlushDelay, func() {
+			_ = rw.Flush()
+		})
+		return
+	}
+	rw.timer.Reset(rw.FlushDelay)
+}
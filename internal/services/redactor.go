@@ -1,297 +1,309 @@
 package services
 
 import (
-	"context"
-	"fmt"
 	"io"
-	"os"
-	"os/exec"
-	"os/signal"
+	"slices"
 	"strings"
-	"syscall"
-	"time"
+	gosync "sync"
 
-	"github.com/aymanbagabas/go-pty"
+	"github.com/EnvSync-Cloud/envsync-cli/internal/domain"
 )
 
+// RedactorService hides values in the output of a command
 type RedactorService interface {
-	RunRedactor(args []string) int
+	// Redact returns text with the values replaced
+	Redact(text string) string
+	// NewWriter returns a writer redacting a stream of output before it reaches
+	// w, values split across writes included. stream names the output in the report.
+	NewWriter(w io.Writer, stream string) *RedactWriter
+}
+
+// RedactOptions selects how values are found and what replaces them
+type RedactOptions struct {
+	Mode domain.RedactMode
+	// Encodings are the transforms of the values that are redacted as well
+	Encodings []domain.RedactEncoding
+	// Placeholder is the template of the replacement, see domain.RedactConfig.Placeholder
+	Placeholder string
+	// Policies are the policies of single keys, keys without one follow Mode
+	Policies map[string]domain.RedactPolicy
+	// Report, when set, counts the redactions of every writer
+	Report *RedactionReport
 }
 
 type redactor struct {
 	redactText []string
+	// encodings are the transforms of the values that are redacted as well
+	encodings []domain.RedactEncoding
+	// mode is DefaultRedactMode when empty
+	mode domain.RedactMode
+	// placeholder is the template of the replacement, generateRedaction when empty
+	placeholder string
+	// keys maps each value to the variable it belongs to
+	keys map[string]string
+	// policies maps variables to their policy
+	policies map[string]domain.RedactPolicy
+	report   *RedactionReport
+
+	matchersOnce gosync.Once
+	// longValues finds the values long enough to be redacted wherever they appear
+	longValues *Matcher
+	// allValues finds every value, short ones included
+	allValues *Matcher
+	// shortValues finds the values only redacted as a word of their own
+	shortValues *Matcher
+	// shortKeys maps the short values to their variable
+	shortKeys map[string]string
+	// patternKeys maps the values and their encoded forms to their variable
+	patternKeys map[string]string
 }
 
+// NewRedactorService returns a redactor for redactText using the default
+// mode and every encoding
 func NewRedactorService(redactText []string) RedactorService {
+	return NewRedactorServiceWithOptions(redactText, RedactOptions{
+		Mode:      domain.DefaultRedactMode,
+		Encodings: domain.RedactEncodings,
+	})
+}
+
+// NewRedactorServiceWithOptions returns a redactor for redactText
+func NewRedactorServiceWithOptions(redactText []string, opts RedactOptions) RedactorService {
 	return &redactor{
-		redactText: redactText,
+		redactText:  redactText,
+		encodings:   opts.Encodings,
+		mode:        opts.Mode,
+		placeholder: opts.Placeholder,
+		policies:    opts.Policies,
+		report:      opts.Report,
 	}
 }
 
-func (r *redactor) RunRedactor(args []string) int {
-	if len(args) == 0 {
-		fmt.Fprintf(os.Stderr, "No command provided\n")
-		return 1
+// NewRedactorServiceForEnv returns a redactor for the values of env. The
+// replacement can name the variable and each key follows its own policy.
+func NewRedactorServiceForEnv(env map[string]string, opts RedactOptions) RedactorService {
+	r := &redactor{
+		encodings:   opts.Encodings,
+		mode:        opts.Mode,
+		placeholder: opts.Placeholder,
+		keys:        make(map[string]string, len(env)),
+		policies:    opts.Policies,
+		report:      opts.Report,
 	}
 
-	// Create a new PTY
-	ptyMaster, err := pty.New()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error creating PTY: %v\n", err)
-		return 1
-	}
-	defer ptyMaster.Close()
-
-	// Create the command using PTY
-	cmd := ptyMaster.Command(args[0], args[1:]...)
-
-	// Set environment variables to force color output
-	cmd.Env = append(os.Environ(),
-		"FORCE_COLOR=1",
-		"CLICOLOR_FORCE=1",
-		"TERM=xterm-256color",
-	)
-
-	// Start the command
-	if err := cmd.Start(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error starting command: %v\n", err)
-		return 1
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
 	}
+	slices.Sort(keys)
 
-	// Handle interrupt signals
-	ctx, cancel := context.WithCancel(context.Background())
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
-
-	// Handle signals in a goroutine
-	go func() {
-		<-sigChan
-		fmt.Fprintf(os.Stderr, "\nReceived interrupt signal, terminating command...\n")
-		cancel()
-		// Forward signal to the child process
-		if cmd.Process != nil {
-			cmd.Process.Signal(os.Interrupt)
-			// Give the process a chance to handle the signal gracefully
-			time.Sleep(100 * time.Millisecond)
-			// If still running, force kill
-			if cmd.ProcessState == nil || !cmd.ProcessState.Exited() {
-				cmd.Process.Kill()
-			}
-		}
-	}()
-
-	// Channels to signal completion
-	outputDone := make(chan int, 1)
-	cmdDone := make(chan int, 1)
-
-	// Handle stdin in a separate goroutine
-	go r.handleStdin(ctx, ptyMaster)
-
-	// Handle stdout/stderr processing
-	go r.handleOutput(ctx, ptyMaster, outputDone)
-
-	// Wait for command completion or context cancellation
-	go func() {
-		exitCode := 0
-		if err := cmd.Wait(); err != nil {
-			if exitError, ok := err.(*exec.ExitError); ok {
-				exitCode = exitError.ExitCode()
-			} else {
-				exitCode = 1
-			}
-		}
-		cmdDone <- exitCode
-	}()
-
-	// Wait for completion or cancellation
-	select {
-	case exitCode := <-cmdDone:
-		// Wait for output processing to finish with timeout
-		select {
-		case <-outputDone:
-		case <-time.After(1 * time.Second):
-			// Timeout waiting for output processing
+	// A value shared by several keys is named after the first one
+	for _, key := range keys {
+		value := env[key]
+		if value == "" || r.policies[key] == domain.RedactPolicyNever {
+			continue
 		}
-		cancel()
-		return exitCode
-	case <-ctx.Done():
-		// Wait for command to finish with timeout
-		select {
-		case exitCode := <-cmdDone:
-			return exitCode
-		case <-time.After(2 * time.Second):
-			// Force kill if still running
-			if cmd.Process != nil {
-				cmd.Process.Kill()
-			}
-			return 1
+		if _, ok := r.keys[value]; !ok {
+			r.keys[value] = key
+			r.redactText = append(r.redactText, value)
 		}
 	}
+
+	return r
 }
 
-func (r *redactor) handleStdin(ctx context.Context, ptyMaster pty.Pty) {
-	// Use a goroutine to handle stdin reading without blocking
-	stdinChan := make(chan []byte, 1)
-	go func() {
-		buffer := make([]byte, 1024)
-		for {
-			n, err := os.Stdin.Read(buffer)
-			if err != nil {
-				if err != io.EOF {
-					fmt.Fprintf(os.Stderr, "Error reading stdin: %v\n", err)
-				}
-				return
-			}
-			if n > 0 {
-				// Make a copy of the buffer to send through channel
-				data := make([]byte, n)
-				copy(data, buffer[:n])
-				select {
-				case stdinChan <- data:
-				case <-ctx.Done():
-					return
-				}
-			}
-		}
-	}()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case data := <-stdinChan:
-			_, writeErr := ptyMaster.Write(data)
-			if writeErr != nil {
-				fmt.Fprintf(os.Stderr, "Error writing to PTY: %v\n", writeErr)
-				return
-			}
-		}
-	}
+func (r *redactor) Redact(text string) string {
+	return r.processAndRedactText(text)
 }
 
-func (r *redactor) handleOutput(ctx context.Context, ptyMaster pty.Pty, done chan<- int) {
-	buffer := make([]byte, 4096)
-	defer func() {
-		done <- 0
-	}()
-
-	// Use a goroutine to handle PTY reading without blocking
-	outputChan := make(chan []byte, 1)
-	errorChan := make(chan error, 1)
-
-	go func() {
-		for {
-			n, err := ptyMaster.Read(buffer)
-			if n > 0 {
-				// Make a copy of the buffer to send through channel
-				data := make([]byte, n)
-				copy(data, buffer[:n])
-				select {
-				case outputChan <- data:
-				case <-ctx.Done():
-					return
-				}
-			}
-			if err != nil {
-				select {
-				case errorChan <- err:
-				case <-ctx.Done():
-				}
-				return
-			}
-		}
-	}()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case data := <-outputChan:
-			// Process and redact the output
-			text := string(data)
-			redactedText := r.processAndRedactText(text)
-
-			// Write the redacted content to stdout
-			_, writeErr := os.Stdout.Write([]byte(redactedText))
-			if writeErr != nil {
-				fmt.Fprintf(os.Stderr, "Error writing output: %v\n", writeErr)
-				return
-			}
-		case err := <-errorChan:
-			if err == io.EOF {
-				// Command finished
-				return
-			}
-			fmt.Fprintf(os.Stderr, "Error reading PTY: %v\n", err)
-			return
+func (r *redactor) NewWriter(w io.Writer, stream string) *RedactWriter {
+	redact := r.processAndRedactText
+	if r.report != nil {
+		redact = func(text string) string {
+			return r.redact(text, func(key string) {
+				r.report.add(key, stream)
+			})
 		}
 	}
+
+	_, all := r.matchers()
+	rw := NewRedactWriter(w, all, redact)
+	// A random looking token is only recognised once it is complete
+	rw.holdTokens = r.mode == domain.RedactModeEntropy
+	return rw
 }
 
 func (r *redactor) processAndRedactText(text string) string {
+	return r.redact(text, nil)
+}
+
+// redact replaces the values in text. onMatch, when set, is called with the
+// key of every replaced value, or an empty key for a token found by entropy.
+func (r *redactor) redact(text string, onMatch func(key string)) string {
 	if len(text) == 0 {
 		return text
 	}
 
-	// Use comprehensive token-based redaction
-	return r.redactTokens(text)
+	switch r.mode {
+	case domain.RedactModeExact:
+		return r.redactExact(text, onMatch)
+	case domain.RedactModeEntropy:
+		return r.redactHighEntropy(r.redactTokens(text, onMatch), onMatch)
+	default:
+		// Use comprehensive token-based redaction
+		return r.redactTokens(text, onMatch)
+	}
+}
+
+// redactExact replaces every value wherever it appears, in one pass
+func (r *redactor) redactExact(text string, onMatch func(key string)) string {
+	_, all := r.matchers()
+	return all.ReplaceAll(text, r.replaceMatch(onMatch))
 }
 
 // redactTokens implements precise value-based redaction
-func (r *redactor) redactTokens(text string) string {
+func (r *redactor) redactTokens(text string, onMatch func(key string)) string {
 	if len(r.redactText) == 0 {
 		return text
 	}
 
-	redactedText := text
+	// Longer values (8+ chars) are replaced wherever they appear, all in one pass
+	long, _ := r.matchers()
+	redactedText := long.ReplaceAll(text, r.replaceMatch(onMatch))
 
-	// Process redaction values by length - longer values first to avoid conflicts
-	for _, valueToRedact := range r.redactText {
-		if valueToRedact == "" {
-			continue
-		}
+	// Shorter values are only redacted if they appear as complete words
+	// to avoid false positives in paths, variable names, etc.
+	return r.redactCompleteWords(redactedText, onMatch)
+}
 
-		// For longer values (8+ chars), do direct replacement
-		if len(valueToRedact) >= 8 {
-			if strings.Contains(redactedText, valueToRedact) {
-				replacement := r.generateRedaction()
-				redactedText = strings.ReplaceAll(redactedText, valueToRedact, replacement)
+// redactedAnywhere reports whether value is replaced wherever it appears,
+// rather than only as a word of its own
+func (r *redactor) redactedAnywhere(value string) bool {
+	return len(value) >= 8 || r.policies[r.keys[value]] == domain.RedactPolicyAlways
+}
+
+// matchers returns the matchers for the values redacted anywhere and for
+// every value, both with the encoded forms of the long values. They are built
+// on first use and shared by every stream of the redactor.
+func (r *redactor) matchers() (long, all *Matcher) {
+	r.matchersOnce.Do(func() {
+		r.patternKeys = make(map[string]string)
+		r.shortKeys = make(map[string]string)
+
+		var longValues, shortValues []string
+		for _, v := range r.redactText {
+			key := r.keys[v]
+			if r.redactedAnywhere(v) {
+				longValues = append(longValues, v)
+			} else if v != "" {
+				shortValues = append(shortValues, v)
+				r.shortKeys[v] = key
+			}
+
+			for _, pattern := range append([]string{v}, EncodedVariants(v, r.encodings)...) {
+				if _, ok := r.patternKeys[pattern]; !ok {
+					r.patternKeys[pattern] = key
+				}
 			}
-		} else {
-			// For shorter values, only redact if they appear as complete words
-			// to avoid false positives in paths, variable names, etc.
-			redactedText = r.redactCompleteWords(redactedText, valueToRedact)
+		}
+		r.longValues = NewMatcher(WithEncodedVariants(longValues, r.encodings))
+		r.allValues = NewMatcher(WithEncodedVariants(r.redactText, r.encodings))
+		r.shortValues = NewMatcher(shortValues)
+	})
+	return r.longValues, r.allValues
+}
+
+// replaceMatch returns the replace function for the matchers, values and
+// encoded values are replaced alike
+func (r *redactor) replaceMatch(onMatch func(key string)) func(Match) string {
+	return func(m Match) string {
+		return r.replacement(m.Pattern, r.patternKeys[m.Pattern], onMatch)
+	}
+}
+
+// replacement returns what replaces text, an occurrence of a value of key
+func (r *redactor) replacement(text, key string, onMatch func(key string)) string {
+	if onMatch != nil {
+		onMatch(key)
+	}
+
+	if r.policies[key] == domain.RedactPolicyPartial {
+		if masked, ok := partialMask(text); ok {
+			return masked
 		}
 	}
 
-	return redactedText
+	if r.placeholder == "" {
+		return r.generateRedaction()
+	}
+	return domain.RenderRedactPlaceholder(r.placeholder, key)
 }
 
-// redactCompleteWords only redacts values that appear as complete words
-func (r *redactor) redactCompleteWords(text, valueToRedact string) string {
-	if valueToRedact == "" {
-		return text
+// partialMask keeps the start of value up to a separator in its first half
+// and its last characters, as in sk_live_****abcd. Values too short to keep
+// anything back are not masked.
+func partialMask(value string) (string, bool) {
+	const visible = 4
+	if len(value) < 3*visible {
+		return "", false
 	}
 
-	// Split text into words and check each one
-	words := strings.Fields(text)
-	for _, word := range words {
-		// Skip if this word appears to be part of a path or system component
-		if r.isPartOfPath(word) {
+	prefix := ""
+	if i := strings.LastIndexAny(value[:len(value)/2], "_-"); i != -1 {
+		prefix = value[:i+1]
+	}
+	return prefix + "****" + value[len(value)-visible:], true
+}
+
+// redactCompleteWords replaces the words of text that are a short value once
+// cleaned of punctuation. The short values are all found in one pass and only
+// the words holding one are looked at, so the value stays visible inside
+// paths and longer words.
+func (r *redactor) redactCompleteWords(text string, onMatch func(key string)) string {
+	r.matchers()
+
+	var b strings.Builder
+	last, wordEnd := 0, 0
+	// Matches come ordered by end, a word is looked at once however many
+	// values it holds
+	for _, match := range r.shortValues.FindAll(text) {
+		if match.Start < wordEnd {
 			continue
 		}
 
-		// Clean the word for comparison (remove punctuation)
-		cleanWord := r.cleanWordForMatching(word)
-
-		// Only redact if the cleaned word exactly matches the value to redact
-		if cleanWord == valueToRedact {
-			replacement := r.generateRedaction()
-			text = strings.ReplaceAll(text, word, replacement)
+		start, end := match.Start, match.Start
+		for start > 0 && !isFieldSpace(text[start-1]) {
+			start--
 		}
+		for end < len(text) && !isFieldSpace(text[end]) {
+			end++
+		}
+		wordEnd = end
+
+		word := text[start:end]
+		// Skip if this word appears to be part of a path or system component,
+		// and only redact if the cleaned word exactly matches a value
+		value := r.cleanWordForMatching(word)
+		key, ok := r.shortKeys[value]
+		if !ok || r.isPartOfPath(word) {
+			continue
+		}
+
+		b.WriteString(text[last:start])
+		b.WriteString(r.replacement(value, key, onMatch))
+		last = end
 	}
 
-	return text
+	if last == 0 {
+		return text
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// isFieldSpace reports whether c separates words, like strings.Fields does for ASCII
+func isFieldSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\v' || c == '\f'
 }
 
 // isPartOfPath checks if a word appears to be part of a file path or variable name
@@ -340,85 +352,6 @@ func (r *redactor) isPartOfPath(word string) bool {
 	return false
 }
 
-// tokenizeText splits text into meaningful tokens using various delimiters
-func (r *redactor) tokenizeText(text string) []string {
-	var tokens []string
-
-	// First split by whitespace to get main chunks
-	fields := strings.Fields(text)
-
-	for _, field := range fields {
-		// Further split each field by common delimiters while preserving the original tokens
-		subTokens := r.splitByDelimiters(field)
-		tokens = append(tokens, subTokens...)
-	}
-
-	return tokens
-}
-
-// splitByDelimiters splits a string by various delimiters but keeps meaningful tokens together
-func (r *redactor) splitByDelimiters(s string) []string {
-	if s == "" {
-		return nil
-	}
-
-	var tokens []string
-	currentToken := ""
-
-	// Common delimiters that might separate tokens
-	delimiters := " \t\n\r,;|&"
-
-	for i, char := range s {
-		if strings.ContainsRune(delimiters, char) {
-			if currentToken != "" {
-				tokens = append(tokens, currentToken)
-				currentToken = ""
-			}
-		} else {
-			currentToken += string(char)
-		}
-
-		// If we're at the end, add the current token
-		if i == len(s)-1 && currentToken != "" {
-			tokens = append(tokens, currentToken)
-		}
-	}
-
-	// Also add the original string as a token to catch cases where
-	// sensitive data spans across what we consider delimiters
-	tokens = append(tokens, s)
-
-	return tokens
-}
-
-// tokenContainsSensitiveData checks if a token contains any sensitive data
-func (r *redactor) tokenContainsSensitiveData(token string) bool {
-	for _, valueToRedact := range r.redactText {
-		if valueToRedact == "" {
-			continue
-		}
-
-		// Check direct containment
-		if strings.Contains(token, valueToRedact) {
-			return true
-		}
-
-		// Check after cleaning the token
-		cleanToken := r.cleanWordForMatching(token)
-		if strings.Contains(cleanToken, valueToRedact) {
-			return true
-		}
-
-		// Check if the sensitive value is contained in the cleaned token
-		cleanValue := r.cleanWordForMatching(valueToRedact)
-		if cleanValue != "" && strings.Contains(cleanToken, cleanValue) {
-			return true
-		}
-	}
-
-	return false
-}
-
 func (r *redactor) cleanWordForMatching(word string) string {
 	// Remove common punctuation and special characters that might be attached to sensitive values
 	cleaned := strings.TrimFunc(word, func(r rune) bool {
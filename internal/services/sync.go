@@ -1,18 +1,29 @@
 package services
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/BurntSushi/toml"
-	"github.com/joho/godotenv"
 
 	"github.com/EnvSync-Cloud/envsync-cli/internal/constants"
 	"github.com/EnvSync-Cloud/envsync-cli/internal/domain"
+	"github.com/EnvSync-Cloud/envsync-cli/internal/dotenv"
 	"github.com/EnvSync-Cloud/envsync-cli/internal/mappers"
 	"github.com/EnvSync-Cloud/envsync-cli/internal/repository"
 )
 
+// ErrCorruptedSnapshot is returned by ReadSnapshot, along with an empty
+// snapshot, when the stored snapshot cannot be decoded
+var ErrCorruptedSnapshot = errors.New("sync snapshot is corrupted")
+
+// stateDirGitignore keeps the local state, which holds plain values, out of git
+const stateDirGitignore = "# Created by envsync, this directory holds plain values\n*\n"
+
 type SyncService interface {
 	ReadConfigData() (domain.SyncConfig, error)
 	WriteConfigData(cfg domain.SyncConfig) error
@@ -22,51 +33,95 @@ type SyncService interface {
 	CalculateEnvDiff(local map[string]string, remote map[string]string) *domain.EnvironmentSync
 	WriteLocalEnv(env map[string]string) error
 	WriteRemoteEnv(env *domain.EnvironmentSync) error
+	ReadSnapshot() (domain.SyncSnapshot, error)
+	WriteSnapshot(variables map[string]string) error
+	ListBackups() ([]domain.EnvBackup, error)
+	ReadBackup(backup domain.EnvBackup) (map[string]string, error)
+	RestoreBackup(backup domain.EnvBackup) error
 }
 
 type sync struct {
 	repo       repository.EnvVariableRepository
 	projectCfg domain.SyncConfig
+	configPath string
+	envFile    string
 }
 
 func NewSyncService() SyncService {
 	var projCfg domain.SyncConfig
-	_ = readTOMLConfig(&projCfg)
+	_ = readTOMLConfig(constants.DefaultProjectConfig, &projCfg)
 
-	r := repository.NewEnvVariableRepository(projCfg.AppID, projCfg.EnvTypeID)
+	return NewSyncServiceForTarget(constants.DefaultProjectConfig, projCfg, domain.SyncTarget{
+		EnvFile: projCfg.EnvFile,
+		EnvType: domain.EnvType{ID: projCfg.EnvTypeID},
+	})
+}
+
+// NewSyncServiceForTarget creates a sync service for one env file of the
+// project configured at configPath. Relative env file paths are resolved
+// against the directory of the config file.
+func NewSyncServiceForTarget(configPath string, cfg domain.SyncConfig, target domain.SyncTarget) SyncService {
+	cfg.EnvTypeID = target.EnvType.ID
+
+	envFile := target.EnvFile
+	if envFile == "" {
+		envFile = constants.DefaultEnvFile
+	}
+	if !filepath.IsAbs(envFile) {
+		envFile = filepath.Join(filepath.Dir(configPath), envFile)
+	}
+
+	r := repository.NewEnvVariableRepository(cfg.AppID, cfg.EnvTypeID)
 
 	return &sync{
 		repo:       r,
-		projectCfg: projCfg,
+		projectCfg: cfg,
+		configPath: configPath,
+		envFile:    envFile,
 	}
 }
 
-func (s *sync) SyncConfigExist() error {
-	if _, err := os.Stat(constants.DefaultProjectConfig); errors.Is(err, os.ErrNotExist) {
-		return errors.New("project configuration file not found")
+// NewSyncServiceFromRepository creates a sync service reading and writing the
+// remote variables through repo. Local files are the defaults of the current
+// directory.
+func NewSyncServiceFromRepository(repo repository.EnvVariableRepository, cfg domain.SyncConfig) SyncService {
+	return &sync{
+		repo:       repo,
+		projectCfg: cfg,
+		configPath: constants.DefaultProjectConfig,
+		envFile:    constants.DefaultEnvFile,
 	}
-	return nil
 }
 
-func (s *sync) ReadConfigData() (domain.SyncConfig, error) {
+// ReadSyncConfig reads the project configuration at path
+func ReadSyncConfig(path string) (domain.SyncConfig, error) {
 	var cfg domain.SyncConfig
-
-	if err := readTOMLConfig(&cfg); err != nil {
+	if err := readTOMLConfig(path, &cfg); err != nil {
 		return domain.SyncConfig{}, err
 	}
-
 	return cfg, nil
 }
 
+func (s *sync) SyncConfigExist() error {
+	if _, err := os.Stat(s.configPath); errors.Is(err, os.ErrNotExist) {
+		return errors.New("project configuration file not found")
+	}
+	return nil
+}
+
+func (s *sync) ReadConfigData() (domain.SyncConfig, error) {
+	return ReadSyncConfig(s.configPath)
+}
+
 func (s *sync) WriteConfigData(cfg domain.SyncConfig) error {
-	if _, err := os.Stat(constants.DefaultProjectConfig); err != nil {
+	if _, err := os.Stat(s.configPath); err != nil {
 		if os.IsNotExist(err) {
-			os.Create(constants.DefaultProjectConfig)
+			os.Create(s.configPath)
 		}
 	}
 
 	// Write the config to the file
-	file, err := os.Create(constants.DefaultProjectConfig)
+	file, err := os.Create(s.configPath)
 	if err != nil {
 		return err
 	}
@@ -92,12 +147,13 @@ func (s *sync) ReadRemoteEnv() ([]*domain.EnvironmentVariable, error) {
 }
 
 func (s *sync) ReadLocalEnv() (map[string]string, error) {
-	if _, err := os.Stat(".env"); os.IsNotExist(err) {
-		// Return empty map if .env file doesn't exist
-		return make(map[string]string), nil
+	// A missing .env file reads as an empty document
+	doc, err := dotenv.ReadFile(s.envFile)
+	if err != nil {
+		return nil, err
 	}
 
-	return godotenv.Read(".env")
+	return doc.Map(), nil
 }
 
 func (s *sync) CalculateEnvDiff(local map[string]string, remote map[string]string) *domain.EnvironmentSync {
@@ -118,7 +174,18 @@ func (s *sync) CalculateEnvDiff(local map[string]string, remote map[string]strin
 }
 
 func (s *sync) WriteLocalEnv(env map[string]string) error {
-	return godotenv.Write(env, ".env")
+	// Only the changed assignments are rewritten, comments and layout are kept
+	doc, err := dotenv.ReadFile(s.envFile)
+	if err != nil {
+		return err
+	}
+
+	if err := s.backupLocalEnv(); err != nil {
+		return fmt.Errorf("failed to back up %s: %w", s.envFileName(), err)
+	}
+
+	doc.Update(env)
+	return doc.WriteFile(s.envFile)
 }
 
 func (s *sync) WriteRemoteEnv(env *domain.EnvironmentSync) error {
@@ -150,8 +217,92 @@ func (s *sync) WriteRemoteEnv(env *domain.EnvironmentSync) error {
 	return nil
 }
 
-func readTOMLConfig(c *domain.SyncConfig) error {
-	if _, err := toml.DecodeFile(constants.DefaultProjectConfig, &c); err != nil {
+// ReadSnapshot returns the last synced state of the configured environment.
+// An empty snapshot is returned when nothing has been synced yet.
+func (s *sync) ReadSnapshot() (domain.SyncSnapshot, error) {
+	snapshot := domain.SyncSnapshot{
+		AppID:     s.projectCfg.AppID,
+		EnvTypeID: s.projectCfg.EnvTypeID,
+		Variables: make(map[string]string),
+	}
+
+	data, err := os.ReadFile(s.snapshotPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return snapshot, nil
+		}
+		return snapshot, err
+	}
+
+	var stored domain.SyncSnapshot
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return snapshot, fmt.Errorf("%w: %s: %v", ErrCorruptedSnapshot, s.snapshotPath(), err)
+	}
+
+	// A snapshot of another application is not a valid merge base
+	if stored.AppID != snapshot.AppID || stored.EnvTypeID != snapshot.EnvTypeID {
+		return snapshot, nil
+	}
+
+	if stored.Variables == nil {
+		stored.Variables = make(map[string]string)
+	}
+
+	return stored, nil
+}
+
+func (s *sync) WriteSnapshot(variables map[string]string) error {
+	snapshot := domain.SyncSnapshot{
+		AppID:      s.projectCfg.AppID,
+		EnvTypeID:  s.projectCfg.EnvTypeID,
+		EnvFile:    s.envFileName(),
+		Variables:  variables,
+		LastSynced: time.Now().UTC(),
+	}
+
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	path := s.snapshotPath()
+	if err := s.makeStateDir(filepath.Dir(path)); err != nil {
+		return err
+	}
+
+	// The snapshot holds plain values, keep it private like the .env file itself
+	return os.WriteFile(path, data, 0600)
+}
+
+func (s *sync) snapshotPath() string {
+	return filepath.Join(
+		s.stateDir(),
+		constants.SnapshotDir,
+		s.projectCfg.EnvTypeID+".json",
+	)
+}
+
+// stateDir returns the directory holding the local sync state of the project
+func (s *sync) stateDir() string {
+	return filepath.Join(filepath.Dir(s.configPath), constants.ProjectStateDir)
+}
+
+// makeStateDir creates dir inside the state directory. The state directory
+// gets a .gitignore of its own so snapshots and backups are never committed.
+func (s *sync) makeStateDir(dir string) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	gitignore := filepath.Join(s.stateDir(), ".gitignore")
+	if _, err := os.Stat(gitignore); err == nil || !os.IsNotExist(err) {
+		return err
+	}
+	return os.WriteFile(gitignore, []byte(stateDirGitignore), 0644)
+}
+
+func readTOMLConfig(path string, c *domain.SyncConfig) error {
+	if _, err := toml.DecodeFile(path, &c); err != nil {
 		return err
 	}
 
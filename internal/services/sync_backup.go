@@ -0,0 +1,188 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/EnvSync-Cloud/envsync-cli/internal/constants"
+	"github.com/EnvSync-Cloud/envsync-cli/internal/domain"
+	"github.com/EnvSync-Cloud/envsync-cli/internal/dotenv"
+)
+
+// backupIDLayout names backups after their UTC creation time, which keeps
+// them sorted by name
+const backupIDLayout = "20060102T150405.000Z"
+
+// ListBackups returns the backups of the env file, newest first
+func (s *sync) ListBackups() ([]domain.EnvBackup, error) {
+	entries, err := os.ReadDir(s.backupDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var backups []domain.EnvBackup
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".env")
+		if !ok || entry.IsDir() {
+			continue
+		}
+		createdAt, err := time.Parse(backupIDLayout, id)
+		if err != nil {
+			continue
+		}
+
+		backups = append(backups, domain.EnvBackup{
+			ID:        id,
+			EnvFile:   s.envFileName(),
+			Path:      filepath.Join(s.backupDir(), entry.Name()),
+			CreatedAt: createdAt,
+		})
+	}
+
+	slices.SortFunc(backups, func(a, b domain.EnvBackup) int {
+		return strings.Compare(b.ID, a.ID)
+	})
+
+	return backups, nil
+}
+
+// ReadBackup returns the variables stored in a backup
+func (s *sync) ReadBackup(backup domain.EnvBackup) (map[string]string, error) {
+	doc, err := dotenv.ReadFile(backup.Path)
+	if err != nil {
+		return nil, err
+	}
+	return doc.Map(), nil
+}
+
+// RestoreBackup replaces the env file with a backup. The current file is
+// backed up first, so a restore can be undone. The sync snapshot of the file
+// is dropped, otherwise the next push would take the restored values for
+// local edits made after the last sync and revert newer remote changes.
+func (s *sync) RestoreBackup(backup domain.EnvBackup) error {
+	data, err := os.ReadFile(backup.Path)
+	if err != nil {
+		return err
+	}
+
+	doc, err := dotenv.Parse(data)
+	if err != nil {
+		return fmt.Errorf("backup %s is corrupted: %w", backup.ID, err)
+	}
+
+	if err := s.backupLocalEnv(); err != nil {
+		return err
+	}
+
+	if err := doc.WriteFile(s.envFile); err != nil {
+		return err
+	}
+
+	return s.removeSnapshots()
+}
+
+// removeSnapshots deletes the snapshots recorded for the env file, so the
+// next sync merges without a base
+func (s *sync) removeSnapshots() error {
+	dir := filepath.Join(s.stateDir(), constants.SnapshotDir)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok || entry.IsDir() {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		// Snapshots written before env_file was recorded are only known by env type
+		if name != s.projectCfg.EnvTypeID || name == "" {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			var stored domain.SyncSnapshot
+			if json.Unmarshal(data, &stored) != nil || stored.EnvFile != s.envFileName() {
+				continue
+			}
+		}
+
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// backupLocalEnv copies the current env file into the backup directory and
+// drops the backups beyond the retention limit
+func (s *sync) backupLocalEnv() error {
+	retention := s.projectCfg.BackupRetention
+	if retention == 0 {
+		retention = constants.DefaultBackupRetention
+	}
+	if retention < 0 {
+		return nil
+	}
+
+	data, err := os.ReadFile(s.envFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	dir := s.backupDir()
+	if err := s.makeStateDir(dir); err != nil {
+		return err
+	}
+
+	id := time.Now().UTC().Format(backupIDLayout)
+	// Backups hold plain values, keep them private
+	if err := os.WriteFile(filepath.Join(dir, id+".env"), data, 0600); err != nil {
+		return err
+	}
+
+	backups, err := s.ListBackups()
+	if err != nil {
+		return err
+	}
+	for _, backup := range backups[min(retention, len(backups)):] {
+		if err := os.Remove(backup.Path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// backupDir returns the directory holding the backups of the env file
+func (s *sync) backupDir() string {
+	name := strings.NewReplacer("/", "_", `\`, "_", ":", "_").Replace(filepath.ToSlash(s.envFileName()))
+
+	return filepath.Join(s.stateDir(), constants.BackupDir, name)
+}
+
+// envFileName returns the env file path relative to the project configuration
+func (s *sync) envFileName() string {
+	rel, err := filepath.Rel(filepath.Dir(s.configPath), s.envFile)
+	if err != nil {
+		return s.envFile
+	}
+	return rel
+}
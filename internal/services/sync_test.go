@@ -0,0 +1,65 @@
+package services
+
+import (
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/EnvSync-Cloud/envsync-cli/internal/constants"
+	"github.com/EnvSync-Cloud/envsync-cli/internal/domain"
+)
+
+func newTestSync(t *testing.T) *sync {
+	dir := t.TempDir()
+	return &sync{
+		projectCfg: domain.SyncConfig{AppID: "app", EnvTypeID: "dev"},
+		configPath: filepath.Join(dir, constants.DefaultProjectConfig),
+		envFile:    filepath.Join(dir, constants.DefaultEnvFile),
+	}
+}
+
+func TestWriteSnapshotIgnoresStateDir(t *testing.T) {
+	s := newTestSync(t)
+
+	variables := map[string]string{"A": "1"}
+	if err := s.WriteSnapshot(variables); err != nil {
+		t.Fatalf("WriteSnapshot returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(s.stateDir(), ".gitignore"))
+	if err != nil {
+		t.Fatalf("Expected a .gitignore in the state directory: %v", err)
+	}
+	if string(data) != stateDirGitignore {
+		t.Errorf("Unexpected .gitignore %q", data)
+	}
+
+	snapshot, err := s.ReadSnapshot()
+	if err != nil {
+		t.Fatalf("ReadSnapshot returned error: %v", err)
+	}
+	if !maps.Equal(snapshot.Variables, variables) {
+		t.Errorf("Expected %v, got %v", variables, snapshot.Variables)
+	}
+}
+
+func TestReadCorruptedSnapshot(t *testing.T) {
+	s := newTestSync(t)
+
+	if err := s.makeStateDir(filepath.Dir(s.snapshotPath())); err != nil {
+		t.Fatalf("makeStateDir returned error: %v", err)
+	}
+	if err := os.WriteFile(s.snapshotPath(), []byte(`{"variables": {`), 0600); err != nil {
+		t.Fatalf("Failed to write snapshot: %v", err)
+	}
+
+	snapshot, err := s.ReadSnapshot()
+	if !errors.Is(err, ErrCorruptedSnapshot) {
+		t.Fatalf("Expected ErrCorruptedSnapshot, got %v", err)
+	}
+	if snapshot.Variables == nil || len(snapshot.Variables) != 0 {
+		t.Errorf("Expected an empty snapshot, got %v", snapshot.Variables)
+	}
+}
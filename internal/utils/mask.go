@@ -0,0 +1,18 @@
+package utils
+
+import "strings"
+
+// MaskValue hides a sensitive value for display. Long values keep their last
+// four characters so that two different values can still be told apart.
+func MaskValue(value string) string {
+	if value == "" {
+		return "(empty)"
+	}
+
+	runes := []rune(value)
+	if len(runes) < 12 {
+		return strings.Repeat("*", 8)
+	}
+
+	return strings.Repeat("*", 8) + string(runes[len(runes)-4:])
+}
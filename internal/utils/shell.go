@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"os"
+	"runtime"
+	"strings"
+)
+
+// ShellCommand returns the argv that runs command through the user's shell:
+// $SHELL -c on Unix, falling back to /bin/sh, and %ComSpec% /C on Windows.
+func ShellCommand(command string) []string {
+	if runtime.GOOS == "windows" {
+		shell := os.Getenv("ComSpec")
+		if shell == "" {
+			shell = "cmd.exe"
+		}
+		return []string{shell, "/C", command}
+	}
+
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		shell = "/bin/sh"
+	}
+	return []string{shell, "-c", command}
+}
+
+// ShellJoin quotes every argument for the shell of ShellCommand and joins
+// them, so the shell splits the result back into the same arguments
+func ShellJoin(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = shellQuote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+func shellQuote(arg string) string {
+	if arg != "" && strings.IndexFunc(arg, needsShellQuote) == -1 {
+		return arg
+	}
+
+	if runtime.GOOS == "windows" {
+		return `"` + strings.ReplaceAll(arg, `"`, `""`) + `"`
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+// needsShellQuote reports whether r has a meaning for the shell
+func needsShellQuote(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		return false
+	}
+	return !strings.ContainsRune("-_./:=,+@%", r)
+}
@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestShellJoinKeepsArguments(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a POSIX shell")
+	}
+
+	args := []string{"grep", "a b", "", "it's", `"quoted"`, "$HOME", "a&&b", "plain-arg=1"}
+
+	out, err := exec.Command("/bin/sh", "-c", `printf '%s\n' `+ShellJoin(args)).Output()
+	if err != nil {
+		t.Fatalf("Failed to run the shell: %v", err)
+	}
+
+	got := strings.Split(strings.TrimSuffix(string(out), "\n"), "\n")
+	if strings.Join(got, "|") != strings.Join(args, "|") {
+		t.Errorf("Expected %q, got %q", args, got)
+	}
+}
@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"os"
+	"sync"
+
+	"github.com/charmbracelet/x/term"
+)
+
+// IsInteractive returns true if both stdin and stdout are attached to a terminal
+func IsInteractive() bool {
+	return term.IsTerminal(os.Stdin.Fd()) && term.IsTerminal(os.Stdout.Fd())
+}
+
+// MakeStdinRaw puts the terminal on stdin in raw mode, so every key press,
+// Ctrl sequences included, is read as is. The returned function restores the
+// previous mode and can be called any number of times. Nothing changes when
+// stdin is not a terminal.
+func MakeStdinRaw() (restore func(), err error) {
+	fd := os.Stdin.Fd()
+	if !term.IsTerminal(fd) {
+		return func() {}, nil
+	}
+
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return func() {}, err
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			term.Restore(fd, state)
+		})
+	}, nil
+}
+
+// RestoreOnPanic calls restore when the goroutine it is deferred in panics,
+// then continues panicking. A panic in any goroutine ends the program
+// without running the deferred calls of the others.
+func RestoreOnPanic(restore func()) {
+	if r := recover(); r != nil {
+		restore()
+		panic(r)
+	}
+}